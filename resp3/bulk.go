@@ -0,0 +1,148 @@
+package resp3
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// DecodeBulkTo decodes a bulk string reply from reader and streams its payload to
+// w instead of materializing it as a string or []byte, so file-sized values can be
+// proxied with constant memory. Streamed bulk strings ("$?") are copied chunk by
+// chunk. The trailing CRLF is consumed, leaving the reader at the next reply.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//   - w io.Writer: The destination for the bulk string payload.
+//
+// Returns:
+//   - int64: The number of payload bytes written to w. A null bulk string, or the
+//     RESP3 null "_", writes nothing.
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is not a bulk string, a decoding error, or the error
+//     returned by w. A reply of another type is consumed entirely before returning.
+//
+// Example usage:
+//
+//	n, err := DecodeBulkTo(reader, file) // "$5\r\nhello\r\n" writes "hello", n == 5
+func DecodeBulkTo(reader *bufio.Reader, w io.Writer) (int64, error) {
+	return (&Decoder{reader: reader}).DecodeBulkTo(w)
+}
+
+// DecodeBulkTo streams the payload of the next reply, which must be a bulk string,
+// to w; see the package-level DecodeBulkTo function.
+func (d *Decoder) DecodeBulkTo(w io.Writer) (int64, error) {
+	start := d.offset
+	defer func() { d.lastFrameSize = int(d.offset - start) }()
+
+	dataType, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if dataType != '$' {
+		// Consume the whole reply so the stream stays aligned
+		value, err := d.decodeFrame(dataType)
+		if err != nil {
+			return 0, d.frameError(dataType, err)
+		}
+		if respErr, ok := value.(*RespError); ok {
+			return 0, respErr
+		}
+		if dataType == '_' {
+			return 0, nil // RESP3 null, written for missing values like "$-1"
+		}
+		return 0, d.replyError(dataType, fmt.Errorf("expected a bulk string reply, got %T: %w", value, ErrProtocol))
+	}
+
+	n, err := d.copyBulkString(w)
+	return n, d.frameError(dataType, err)
+}
+
+// copyBulkString copies the payload of a bulk string whose type marker has already
+// been read to w.
+func (d *Decoder) copyBulkString(w io.Writer) (int64, error) {
+	lengthStr, err := d.readLine()
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	streamed := lengthStr == "?"
+	var written int64
+
+	for {
+		if streamed {
+			if lengthStr, err = d.readChunkHeader(); err != nil {
+				return written, err
+			}
+		}
+
+		length, err := parseLength(lengthStr)
+		if err != nil {
+			return written, err
+		}
+
+		if length == -1 && !streamed {
+			return 0, nil // Null bulk string
+		}
+		if length < 0 {
+			return written, fmt.Errorf("invalid streamed string chunk length %d: %w", length, ErrProtocol)
+		}
+		if length == 0 && streamed {
+			return written, nil // Terminator chunk
+		}
+
+		n, err := d.copyPayload(w, int64(length))
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		if err := d.readCRLF(); err != nil {
+			return written, err
+		}
+
+		if !streamed {
+			return written, nil
+		}
+	}
+}
+
+// readChunkHeader reads the ";<len>\r\n" header of a streamed string chunk and
+// returns its length text.
+func (d *Decoder) readChunkHeader() (string, error) {
+	marker, err := d.readByte()
+	if err == io.EOF {
+		return "", io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", err
+	}
+	if marker != ';' {
+		return "", fmt.Errorf("expected streamed string chunk, found %q: %w", marker, ErrProtocol)
+	}
+
+	lengthStr, err := d.readLine()
+	if err == io.EOF {
+		return "", io.ErrUnexpectedEOF
+	}
+	return lengthStr, err
+}
+
+// copyPayload copies exactly length bytes from the stream to w, and to the tee
+// writer if one is set. Running out of data first is reported as io.ErrUnexpectedEOF.
+func (d *Decoder) copyPayload(w io.Writer, length int64) (int64, error) {
+	if d.Tee != nil {
+		w = io.MultiWriter(w, d.Tee)
+	}
+
+	n, err := io.CopyN(w, d.reader, length)
+	d.offset += n
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
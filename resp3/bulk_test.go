@@ -0,0 +1,72 @@
+package resp3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBulkTo(t *testing.T) {
+	large := strings.Repeat("x", 3*maxPooledBufferSize)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Bulk string", input: "$5\r\nhello\r\n", expected: "hello"},
+		{name: "Empty bulk string", input: "$0\r\n\r\n", expected: ""},
+		{name: "Null bulk string", input: "$-1\r\n", expected: ""},
+		{name: "RESP3 null", input: "_\r\n", expected: ""},
+		{name: "Streamed bulk string", input: "$?\r\n;3\r\nfoo\r\n;3\r\nbar\r\n;0\r\n", expected: "foobar"},
+		{name: "Larger than the pooled buffers", input: "$" + strconv.Itoa(len(large)) + "\r\n" + large + "\r\n", expected: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newReader(tt.input + "+next\r\n")
+
+			var buf bytes.Buffer
+			n, err := DecodeBulkTo(reader, &buf)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if n != int64(len(tt.expected)) || buf.String() != tt.expected {
+				t.Errorf("expected %d bytes, got %d (%.20q)", len(tt.expected), n, buf.String())
+			}
+
+			// The trailing CRLF must have been consumed
+			if next, err := Decode(reader); err != nil || next != "next" {
+				t.Errorf("expected next frame, got %v (err %v)", next, err)
+			}
+		})
+	}
+}
+
+func TestDecodeBulkToErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	reader := newReader("*1\r\n$1\r\na\r\n+next\r\n")
+	if _, err := DecodeBulkTo(reader, &buf); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for an array reply, got %v", err)
+	}
+	if next, err := Decode(reader); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+
+	var respErr *RespError
+	if _, err := DecodeBulkTo(newReader("-ERR boom\r\n"), &buf); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+
+	if _, err := DecodeBulkTo(newReader("$5\r\nhel"), &buf); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF for a truncated payload, got %v", err)
+	}
+
+	if _, err := DecodeBulkTo(newReader("$5\r\nhelloXX"), &buf); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a wrong terminator, got %v", err)
+	}
+}
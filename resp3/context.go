@@ -0,0 +1,57 @@
+package resp3
+
+import (
+	"bufio"
+	"context"
+)
+
+// DecodeContext behaves like Decode, but returns ctx.Err() if the context is
+// done before the next value has been decoded, e.g. to abort a read blocked on an
+// idle connection during shutdown.
+//
+// A bufio.Reader cannot be interrupted, so the decode runs in a separate goroutine
+// that stays parked in the underlying read after cancellation. To release it the
+// caller must unblock the underlying io.Reader, typically by closing the net.Conn
+// or setting a read deadline with SetReadDeadline; the goroutine then exits on its
+// own. After a cancellation the reader is left mid-frame and must not be used
+// again.
+//
+// Parameters:
+//   - ctx context.Context: Cancels the wait for the next value.
+//   - reader *bufio.Reader: The reader to decode from, see Decode.
+//
+// Returns:
+//   - interface{}: The decoded value, see Decode.
+//   - error: ctx.Err() if the context was done first, otherwise the decode error.
+//
+// Example usage:
+//
+//	go func() {
+//	    <-ctx.Done()
+//	    conn.Close() // unblocks the pending read
+//	}()
+//	value, err := DecodeContext(ctx, bufio.NewReader(conn))
+func DecodeContext(ctx context.Context, reader *bufio.Reader) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	// Buffered so the goroutine can always deliver its result and exit
+	done := make(chan result, 1)
+	go func() {
+		value, err := Decode(reader)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
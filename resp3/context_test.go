@@ -0,0 +1,43 @@
+package resp3
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDecodeContext(t *testing.T) {
+	result, err := DecodeContext(context.Background(), newReader("+OK\r\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result != "OK" {
+		t.Errorf("expected OK, got %v", result)
+	}
+}
+
+func TestDecodeContextAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := DecodeContext(ctx, newReader("+OK\r\n")); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDecodeContextCancelledWhileBlocked(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close() // Unblocks the pending read so the goroutine exits
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The peer never sends anything, so the read blocks until the deadline
+	_, err := DecodeContext(ctx, bufio.NewReader(pipeReader))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
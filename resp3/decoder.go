@@ -2,10 +2,16 @@ package resp3
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // Decode reads from the provided bufio.Reader and interprets the next RESP3 data type,
@@ -22,13 +28,284 @@ import (
 //
 //   - interface{}: The decoded data from the reader. The actual type of the returned value can be
 //     one of several Go types depending on the RESP3 data type encountered. This could be a string
-//     for Simple Strings and Bulk Strings, error for RESP3 Errors, int64 for Integers, float64 for
-//     Floats, []interface{} for Arrays, map[string]interface{} for Maps, bool for Booleans, or nil
-//     for Nulls.
+//     for Simple Strings and Bulk Strings, *RespError for RESP3 Errors and Blob Errors, int64 for
+//     Integers (uint64 for those above math.MaxInt64, such as an encoded math.MaxUint64),
+//     float64 for Floats, VerbatimString for Verbatim Strings, []interface{} for Arrays,
+//     Set for Sets, map[string]interface{} for Maps, PushMessage for Pushes, bool for Booleans,
+//     Attributed for replies preceded by attributes, or nil for Nulls.
+//
+//     Maps are narrowed by their keys: map[string]interface{} when every key is a string
+//     (or the map is empty), map[int64]interface{} when every key is an integer, and
+//     map[interface{}]interface{} otherwise. Null keys are dropped. The rule applies to
+//     each map on its own, so a map nested in an array or another map is narrowed by
+//     its own keys whatever its parent's type is.
+//
+//     Note that error replies ("-ERR ..." and blob errors) are values, not failures: they
+//     are returned here as a *RespError, which implements error, while the error result
+//     stays nil. Always check the value for *RespError, or use a Decoder with
+//     ErrorsAsError set to receive them through the error result instead.
+//
+//     Streamed aggregates ("*?", "~?", "%?") are decoded element by element up to their
+//     "." terminator, and streamed bulk strings ("$?") chunk by chunk up to their ";0"
+//     terminator; both are returned in the same form as their counted counterparts.
 //
 //     Sample input string: "*5\r\n$4\r\nMSET\r\n$4\r\nkey1\r\n$16\r\nvalue1 dash dash\r\n$4\r\nkey2\r\n$6\r\nvalue2\r\n"
 func Decode(reader *bufio.Reader) (interface{}, error) {
-	dataType, err := reader.ReadByte()
+	return (&Decoder{reader: reader}).Decode()
+}
+
+// DecodeAll decodes consecutive RESP3 frames from reader until it is exhausted.
+// Every frame is returned as a separate value, which also makes non-standard
+// extensions visible to the caller, e.g. a checksum frame a deployment appends
+// after each reply shows up as its own element (see DecodeWithTrailer).
+//
+// Returns:
+//   - []interface{}: The decoded frames, in order.
+//   - error: nil if the input ended cleanly on a frame boundary, otherwise the
+//     error that stopped decoding, returned along with the frames decoded so far.
+func DecodeAll(reader *bufio.Reader) ([]interface{}, error) {
+	decoder := &Decoder{reader: reader}
+	values := []interface{}{}
+
+	for {
+		value, err := decoder.Decode()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+}
+
+// PeekType returns the type marker of the next frame (e.g. '>' for a Push, '*' for
+// an Array) without consuming it, so a caller multiplexing a connection can decide
+// how to handle the frame before decoding it.
+//
+// Returns:
+//   - byte: The type marker of the next frame.
+//   - error: io.EOF if no more data is available, or the underlying read error.
+func PeekType(reader *bufio.Reader) (byte, error) {
+	next, err := reader.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return next[0], nil
+}
+
+// Decoder reads RESP3 values from a buffered reader. Its exported fields are
+// options that may be set before decoding; the zero value of each option keeps
+// the behaviour of the package-level Decode function.
+type Decoder struct {
+	reader *bufio.Reader
+	offset int64 // Bytes consumed from reader so far
+
+	lastFrameSize int  // Bytes consumed by the most recent Decode call
+	depth         int  // Aggregates currently being decoded, see MaxDepth
+	desynced      bool // A frame was abandoned part way through, see Synced
+
+	// MaxDepth limits how deeply aggregates may be nested. Decoding input that
+	// nests deeper fails with ErrMaxDepthExceeded rather than exhausting the stack
+	// on hostile input. Zero selects a default of 512 levels.
+	MaxDepth int
+
+	// Tee, when non-nil, receives a copy of every byte consumed while decoding,
+	// including the bytes of nested aggregate elements, so the exact wire frames
+	// can be logged or captured alongside the decoded values.
+	Tee io.Writer
+
+	// NativeInt returns integers as int when they fit in the platform int and
+	// as int64 otherwise. On 64-bit platforms every RESP3 integer fits, so the
+	// result is always int; on 32-bit platforms values outside the int32 range
+	// still come back as int64, so callers targeting both must handle either.
+	// Maps keyed by integers are still returned as map[int64]interface{}.
+	NativeInt bool
+
+	// RawBytes returns the payload of bulk strings as []byte instead of string,
+	// avoiding a copy for binary data. Verbatim strings are returned the same way,
+	// as the []byte content following their "<format>:" prefix. Map keys and set
+	// members are still returned as string, since a []byte cannot be hashed.
+	RawBytes bool
+
+	// UniformMapType always returns maps as map[interface{}]interface{}, instead
+	// of narrowing them to map[string]interface{} or map[int64]interface{} when
+	// all keys share that type, so callers only need to handle a single map type.
+	UniformMapType bool
+
+	// RejectDuplicateKeys makes decoding a map fail with ErrProtocol when a key
+	// appears more than once. By default the last occurrence wins.
+	RejectDuplicateKeys bool
+
+	// PoolAggregates draws the backing slices of decoded arrays from a shared
+	// pool instead of allocating them. Callers that are done with a decoded value
+	// hand its arrays back with Release, after which the value must not be used.
+	PoolAggregates bool
+
+	// PreserveOrder returns maps as an OrderedMap holding the entries in the
+	// order the server sent them, instead of as a Go map, which loses that order.
+	PreserveOrder bool
+
+	// OrderedSets returns sets as []interface{} holding the elements in the
+	// order the server sent them, instead of as a Set, which loses that order.
+	OrderedSets bool
+
+	// SimpleStrings returns simple strings ("+OK") as SimpleString rather than
+	// string, so status replies stay distinguishable from bulk string data and
+	// encode back to the same wire form. Maps keyed by simple strings are then
+	// returned as map[interface{}]interface{}.
+	SimpleStrings bool
+
+	// ErrorsAsError returns a top-level error reply as the error result of
+	// Decode, with a nil value, instead of as a *RespError value with a nil
+	// error. Error replies nested inside aggregates, such as the replies of a
+	// transaction, are still returned as values.
+	ErrorsAsError bool
+
+	// AllowInline accepts inline commands, plain text lines such as "PING\r\n"
+	// or "SET foo bar\r\n" that Redis servers accept from clients like telnet.
+	// A top-level frame whose first byte is not a type marker is read up to its
+	// CRLF and returned as a []string of its whitespace-separated arguments; a
+	// blank line yields an empty slice.
+	AllowInline bool
+
+	// RESP2Only rejects every type marker that RESP2 lacks, such as "%", "#",
+	// ",", "_", "~", ">", "|", "(", "=" and "!", with ErrUnsupportedRespDataType
+	// instead of decoding it, which surfaces a protocol version mismatch early.
+	RESP2Only bool
+
+	// UseBigFloat decodes doubles as *big.Float parsed from their original text
+	// instead of as float64, preserving decimal digits that a float64 cannot hold.
+	UseBigFloat bool
+
+	// TimeFormat selects how DecodeTime reads integer timestamps, matching the
+	// Encoder option of the same name: as Unix seconds under UnixSeconds and as
+	// Unix milliseconds otherwise. RFC 3339 strings are accepted under any format.
+	TimeFormat TimeFormat
+
+	// Values returns every frame as a Value tagged with its wire type, instead
+	// of as the native Go types described on the package-level Decode function,
+	// so that distinctions such as simple versus bulk strings are kept. Options
+	// that choose between native Go types do not apply to Values.
+	Values bool
+}
+
+// NewDecoder returns a Decoder that reads from r. If r is already a
+// *bufio.Reader with the default buffer size or larger it is used as is,
+// otherwise it is wrapped in a new bufio.Reader.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{reader: bufio.NewReader(r)}
+}
+
+// NewDecoderSize returns a Decoder that reads from r through a bufio.Reader with
+// a buffer of at least size bytes, reusing r if it already is one. Payloads of
+// any size are read straight through the buffer, so size only trades memory for
+// fewer reads from r.
+func NewDecoderSize(r io.Reader, size int) *Decoder {
+	return &Decoder{reader: bufio.NewReaderSize(r, size)}
+}
+
+// Decode reads the next RESP3 value from the underlying reader. See the
+// package-level Decode function for the mapping of RESP3 types to Go types.
+//
+// Malformed input is reported as a *DecodeError recording the offset and frame
+// type at which decoding failed; io.EOF and io.ErrUnexpectedEOF, which signal
+// that data ran out rather than that it is malformed, are returned unwrapped.
+//
+// Reads block until the data of the frame has arrived, so a Decoder can be used
+// directly on a connection where a frame may arrive in several pieces. io.EOF is
+// only returned when the stream ends cleanly before a frame starts, while a
+// stream ending inside a frame is reported as io.ErrUnexpectedEOF.
+//
+// An error reply is a successfully decoded value: unless ErrorsAsError is set it
+// is returned as a *RespError in the value slot, with a nil error.
+func (d *Decoder) Decode() (interface{}, error) {
+	start := d.offset
+
+	var value interface{}
+	var err error
+	if d.AllowInline && d.atInlineCommand() {
+		value, err = d.decodeInline()
+		d.desynced = d.desynced || err != nil
+	} else if d.Values {
+		value, err = d.decodeValue()
+	} else {
+		value, err = d.decode()
+	}
+	d.lastFrameSize = int(d.offset - start)
+
+	if respErr, ok := value.(*RespError); ok && d.ErrorsAsError {
+		return nil, respErr
+	}
+	if v, ok := value.(Value); ok && v.Err != nil && d.ErrorsAsError {
+		return nil, v.Err
+	}
+	return value, err
+}
+
+const (
+	// defaultMaxDepth is the nesting limit used when MaxDepth is not set.
+	defaultMaxDepth = 512
+
+	// maxPreallocatedElements caps the room reserved up front for the elements of
+	// an aggregate, so a forged count cannot force a huge allocation.
+	maxPreallocatedElements = 1024
+)
+
+// typeMarkers holds the first byte of every RESP3 frame type.
+const typeMarkers = "+-:$*_#,(=!%~|>"
+
+// atInlineCommand reports whether the next frame starts with a byte that is not
+// a type marker, and is therefore an inline command.
+func (d *Decoder) atInlineCommand() bool {
+	next, err := d.reader.Peek(1)
+	return err == nil && strings.IndexByte(typeMarkers, next[0]) < 0
+}
+
+// decodeInline reads an inline command line and splits it into its arguments.
+func (d *Decoder) decodeInline() ([]string, error) {
+	line, err := d.readLine()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return strings.Fields(line), nil
+}
+
+// Synced reports whether the stream is still positioned at the start of a reply,
+// so that the next call to Decode can be trusted. It becomes false for good once
+// a call gives up part way through a frame, leaving the rest of the frame to be
+// misread as the following replies; a client should then close the connection.
+//
+// The following failures leave the stream in sync, as they happen before a reply
+// starts or after it has been consumed in full:
+//
+//   - io.EOF, which is only returned when the stream ends between replies.
+//   - A read error hit before the first byte of a reply.
+//   - Error replies, whether returned as values or, under ErrorsAsError, as errors.
+//   - Replies of an unexpected type or content rejected by DecodeBulkTo, the
+//     Read*Header methods and the typed decoders such as DecodeInt.
+//
+// Every other failure leaves the stream out of sync: malformed input, limits such
+// as MaxDepth being exceeded, io.ErrUnexpectedEOF, read errors in the middle of a
+// reply and errors from the writer passed to DecodeBulkTo. A Read*Header call that
+// fails after the header was read, such as on a streamed or odd-sized aggregate,
+// leaves its elements unread and is out of sync as well.
+func (d *Decoder) Synced() bool {
+	return !d.desynced
+}
+
+// LastFrameSize returns the number of bytes consumed by the most recent call to
+// Decode, including the bytes of nested elements and, if it failed, the bytes
+// consumed before the failure. It is useful for metrics and flow control.
+func (d *Decoder) LastFrameSize() int {
+	return d.lastFrameSize
+}
+
+func (d *Decoder) decode() (interface{}, error) {
+	dataType, err := d.readByte()
 
 	if err != nil {
 		if err == io.EOF {
@@ -37,9 +314,43 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 		return nil, err
 	}
 
+	value, err := d.decodeFrame(dataType)
+	return value, d.frameError(dataType, err)
+}
+
+// frameError reports a failure part way through a frame of the given type,
+// which leaves the stream out of sync, see replyError.
+func (d *Decoder) frameError(dataType byte, err error) error {
+	if err != nil {
+		d.desynced = true
+	}
+	return d.replyError(dataType, err)
+}
+
+// replyError reports malformed input in a frame of the given type with its
+// position as a *DecodeError. Running out of data is not wrapped. Unlike
+// frameError it is also used for replies that were consumed in full but are
+// rejected anyway, such as a reply of the wrong type, which keep the stream
+// in sync.
+func (d *Decoder) replyError(dataType byte, err error) error {
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			err = &DecodeError{Offset: d.offset, Type: dataType, Err: err}
+		}
+	}
+	return err
+}
+
+// decodeFrame decodes the rest of a frame whose type marker has already been read.
+func (d *Decoder) decodeFrame(dataType byte) (interface{}, error) {
+	if err := d.checkType(dataType); err != nil {
+		return nil, err
+	}
+
 	switch dataType {
 	case '+': // Simple String
-		line, err := readLineCRLF(reader)
+		line, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -49,10 +360,13 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
+		if d.SimpleStrings {
+			return SimpleString(line), nil
+		}
 		return string(line), nil
 
 	case '-': // Error
-		line, err := readLineCRLF(reader)
+		line, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -62,10 +376,10 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
-		return errors.New(string(line)), nil
+		return newRespError(string(line)), nil
 
 	case ':': // Integer
-		line, err := readLineCRLF(reader)
+		line, err := d.readLine()
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
 		}
@@ -77,14 +391,23 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, io.ErrUnexpectedEOF
 		}
 
-		xint, castErr := strconv.Atoi(string(line))
+		xint, castErr := strconv.ParseInt(string(line), 10, 64)
+		if errors.Is(castErr, strconv.ErrRange) && line[0] != '-' {
+			if xuint, err := strconv.ParseUint(strings.TrimPrefix(line, "+"), 10, 64); err == nil {
+				return xuint, nil // Above math.MaxInt64, as encoded for large uint64 values
+			}
+		}
 		if castErr != nil {
-			return nil, castErr
+			return nil, fmt.Errorf("invalid integer %q: %w: %w", line, ErrProtocol, castErr)
+		}
+
+		if d.NativeInt && xint >= math.MinInt && xint <= math.MaxInt {
+			return int(xint), nil
 		}
-		return int64(xint), nil
+		return xint, nil
 
 	case ',': // Float
-		line, err := readLineCRLF(reader)
+		line, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -94,14 +417,18 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
+		if d.UseBigFloat {
+			return parseBigFloat(line)
+		}
+
 		xfloat, castErr := strconv.ParseFloat(string(line), 64)
 		if castErr != nil {
-			return xfloat, castErr
+			return nil, fmt.Errorf("invalid double %q: %w: %w", line, ErrProtocol, castErr)
 		}
 		return float64(xfloat), nil
 
 	case '$': // Bulk String
-		lengthStr, err := readLineCRLF(reader)
+		lengthStr, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -111,7 +438,11 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
-		length, err := strconv.Atoi(string(lengthStr))
+		if lengthStr == "?" {
+			return d.decodeStreamedString() // Streamed bulk string
+		}
+
+		length, err := parseLength(lengthStr)
 		if err != nil {
 			return nil, err
 		}
@@ -120,29 +451,31 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, nil // Null bulk string
 		}
 
-		if reader.Buffered() < length+2 { // +2 for the trailing \r\n
-			return nil, io.ErrUnexpectedEOF
-		}
-
-		value := make([]byte, length)
-		_, err = reader.Read(value)
-
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			return nil, io.ErrUnexpectedEOF
+		var value []byte
+		if d.RawBytes || length > maxPooledBufferSize {
+			value, err = d.readPayload(length) // Handed to the caller or too large, so never pooled
+		} else {
+			buf := getBuffer(length)
+			defer putBuffer(buf)
+			value = *buf
+			err = d.readFull(value)
 		}
 
 		if err != nil {
 			return nil, err
 		}
-		_, err = reader.Discard(2)
 
-		if err != nil {
+		if err := d.readCRLF(); err != nil {
 			return nil, err
 		}
+
+		if d.RawBytes {
+			return value, nil
+		}
 		return string(value), nil
 
 	case '=': // Verbatim String
-		lengthStr, err := readLineCRLF(reader)
+		lengthStr, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -152,7 +485,7 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
-		length, err := strconv.Atoi(string(lengthStr))
+		length, err := parseLength(lengthStr)
 		if err != nil {
 			return nil, err
 		}
@@ -161,70 +494,106 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, nil // Null verbatim string
 		}
 
-		if length == 0 {
-			return "", nil // Empty verbatim string
-		}
+		if length == 0 { // Empty verbatim string, only the trailing \r\n follows
+			if err := d.readCRLF(); err != nil {
+				return nil, err
+			}
 
-		if reader.Buffered() < length+2 { // +2 for the trailing \r\n
-			return nil, io.ErrUnexpectedEOF
+			if d.RawBytes {
+				return []byte{}, nil
+			}
+			return VerbatimString{}, nil
 		}
 
-		value := make([]byte, length)
-		_, err = reader.Read(value)
-
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			return nil, io.ErrUnexpectedEOF
+		var value []byte
+		if d.RawBytes || length > maxPooledBufferSize {
+			value, err = d.readPayload(length) // Handed to the caller or too large, so never pooled
+		} else {
+			buf := getBuffer(length)
+			defer putBuffer(buf)
+			value = *buf
+			err = d.readFull(value)
 		}
 
 		if err != nil {
 			return nil, err
 		}
-		reader.Discard(2) // Discard trailing \r\n
-		return string(value), nil
 
-	case '*': // Array
-		countStr, err := readLineCRLF(reader)
+		if err := d.readCRLF(); err != nil {
+			return nil, err
+		}
 
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			return nil, io.ErrUnexpectedEOF
+		if d.RawBytes {
+			if hasVerbatimFormat(value) {
+				return value[4:], nil
+			}
+			return value, nil
 		}
+		return newVerbatimString(string(value)), nil
 
+	case '*': // Array
+		count, streamed, err := d.readCount()
 		if err != nil {
 			return nil, err
 		}
 
-		count, err := strconv.Atoi(string(countStr))
+		if count == -1 {
+			return nil, nil // Null array
+		}
+
+		return d.decodeElements(count, streamed)
+
+	case '>': // Push
+		count, streamed, err := d.readCount()
 		if err != nil {
 			return nil, err
 		}
 
 		if count == -1 {
-			return nil, nil // Null array
+			return nil, fmt.Errorf("push has no null form: %w", ErrProtocol)
 		}
 
-		array := make([]interface{}, count)
+		elements, err := d.decodeElements(count, streamed)
+		if err != nil {
+			return nil, err
+		}
+		return PushMessage(elements), nil
 
-		for i := 0; i < count; i++ {
-			if reader.Buffered() == 0 {
-				return nil, io.ErrUnexpectedEOF // Not enough data to proceed, wait for more
-			}
+	case '~': // Set
+		count, streamed, err := d.readCount()
+		if err != nil {
+			return nil, err
+		}
 
-			element, err := Decode(reader)
+		if count == -1 {
+			return nil, nil // Null set
+		}
 
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return nil, io.ErrUnexpectedEOF
-			}
+		elements, err := d.decodeElements(count, streamed)
+		if err != nil {
+			return nil, err
+		}
 
-			if err != nil {
-				return nil, err
+		if d.OrderedSets {
+			return elements, nil
+		}
+
+		set := make(Set, len(elements))
+		for _, element := range elements {
+			element = hashableKey(element)
+			if element != nil && !reflect.TypeOf(element).Comparable() {
+				return nil, fmt.Errorf("set element of type %T is not hashable: %w", element, ErrProtocol)
 			}
+			set[element] = struct{}{}
+		}
 
-			array[i] = element
+		if d.PoolAggregates {
+			putElements(elements) // Only used as scratch space
 		}
-		return array, nil
+		return set, nil
 
 	case '#': // Boolean
-		b, err := reader.ReadByte()
+		b, err := d.readByte()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -234,77 +603,69 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return false, err
 		}
 
-		reader.Discard(2)
+		if b != 't' && b != 'f' {
+			return nil, fmt.Errorf("invalid boolean value %q: %w", b, ErrProtocol)
+		}
+
+		if err := d.readCRLF(); err != nil {
+			return nil, err
+		}
 		return b == 't', nil
 
 	case '%': // Map of interface{}
-		line, _ := readLineCRLF(reader)
-		size, _ := strconv.Atoi(string(line))
+		size, streamed, err := d.readCount()
+		if err != nil {
+			return nil, err
+		}
 
-		tempMap := make(map[interface{}]interface{}, size/2)
-		isAllStringKeys := true
-		isAllInt64Keys := true
+		if size == -1 {
+			return nil, nil // Null map
+		}
 
-		for i := 0; i < size; i += 2 {
-			if reader.Buffered() == 0 {
-				return nil, io.ErrUnexpectedEOF
-			}
+		elements, err := d.decodeElements(size, streamed)
+		if err != nil {
+			return nil, err
+		}
 
-			key, err := Decode(reader)
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return nil, io.ErrUnexpectedEOF
-			}
-			if err != nil {
-				return nil, err
-			}
+		if len(elements)%2 != 0 {
+			return nil, fmt.Errorf("map has a key without a value: %w", ErrProtocol)
+		}
 
-			value, err := Decode(reader)
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return nil, io.ErrUnexpectedEOF
-			}
-			if err != nil {
-				return nil, err
-			}
+		if d.PreserveOrder {
+			return d.newOrderedMap(elements)
+		}
+
+		tempMap := make(map[interface{}]interface{}, len(elements)/2)
+
+		for i := 0; i < len(elements); i += 2 {
+			key, value := hashableKey(elements[i]), elements[i+1]
 
 			if key == nil {
 				continue
 			}
 
-			switch key.(type) {
-			case string:
-				isAllInt64Keys = false // Mark that not all keys are int64
-			case int64:
-				isAllStringKeys = false // Mark that not all keys are strings
-			default:
-				isAllStringKeys = false
-				isAllInt64Keys = false
+			if !reflect.TypeOf(key).Comparable() {
+				return nil, fmt.Errorf("map key of type %T is not hashable, use PreserveOrder to decode it: %w", key, ErrProtocol)
+			}
+
+			if _, exists := tempMap[key]; exists && d.RejectDuplicateKeys {
+				return nil, fmt.Errorf("duplicate map key %v: %w", key, ErrProtocol)
 			}
 
 			tempMap[key] = value
 		}
 
-		// Based on the keys' types, return the appropriate map type
-		if isAllStringKeys {
-			finalMap := make(map[string]interface{}, size/2)
-			for k, v := range tempMap {
-				finalMap[k.(string)] = v
-			}
-			return finalMap, nil
+		if d.PoolAggregates {
+			putElements(elements) // Only used as scratch space
 		}
 
-		if isAllInt64Keys {
-			finalMap := make(map[int64]interface{}, size/2)
-			for k, v := range tempMap {
-				finalMap[k.(int64)] = v
-			}
-			return finalMap, nil
+		if d.UniformMapType {
+			return tempMap, nil
 		}
-
-		// Otherwise, return the generic map[interface{}]interface{}
-		return tempMap, nil
+		return narrowMap(tempMap), nil
 
 	case '!': // Blob Error
-		lengthStr, err := readLineCRLF(reader)
+		lengthStr, err := d.readLine()
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return nil, io.ErrUnexpectedEOF
@@ -314,34 +675,471 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 			return nil, err
 		}
 
-		length, err := strconv.Atoi(string(lengthStr))
+		length, err := parseLength(lengthStr)
 
 		if err != nil {
 			return nil, err
 		}
 
-		if reader.Buffered() < length+2 {
-			return nil, io.ErrUnexpectedEOF
+		if length == -1 {
+			return nil, fmt.Errorf("blob error has no null form: %w", ErrProtocol)
 		}
 
-		value := make([]byte, length)
-		_, err = reader.Read(value)
+		var value []byte
+		if length > maxPooledBufferSize {
+			value, err = d.readPayload(length) // Too large to pool
+		} else {
+			buf := getBuffer(length)
+			defer putBuffer(buf)
+			value = *buf
+			err = d.readFull(value)
+		}
 
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			return nil, io.ErrUnexpectedEOF
+		if err != nil {
+			return nil, err
+		}
+
+		if err := d.readCRLF(); err != nil {
+			return nil, err
+		}
+		return newRespError(string(value)), nil
+
+	case '|': // Attributes, followed by the value they describe
+		size, streamed, err := d.readCount()
+		if err != nil {
+			return nil, err
+		}
+
+		if size == -1 {
+			return nil, fmt.Errorf("attributes have no null form: %w", ErrProtocol)
+		}
+
+		elements, err := d.decodeElements(size, streamed)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(elements)%2 != 0 {
+			return nil, fmt.Errorf("attributes have a key without a value: %w", ErrProtocol)
+		}
+
+		attributes := make(map[string]interface{}, len(elements)/2)
+		for i := 0; i < len(elements); i += 2 {
+			key, ok := stringValue(elements[i])
+			if !ok {
+				return nil, fmt.Errorf("attribute key is %T, not a string: %w", elements[i], ErrProtocol)
+			}
+			attributes[key] = elements[i+1]
+		}
+
+		if d.PoolAggregates {
+			putElements(elements) // Only used as scratch space
 		}
 
+		value, err := d.decode()
 		if err != nil {
 			return nil, err
 		}
-		reader.Discard(2)
-		return errors.New(string(value)), nil
+		return Attributed{Attributes: attributes, Value: value}, nil
 
 	case '_':
-		reader.Discard(2) // Discard the trailing \r\n
+		if err := d.readCRLF(); err != nil {
+			return nil, err
+		}
 		return nil, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported datatype found: %v: %w", dataType, ErrUnsupportedRespDataType)
 	}
 }
+
+// checkType rejects the types that RESP2 lacks when RESP2Only is set.
+func (d *Decoder) checkType(dataType byte) error {
+	if d.RESP2Only && strings.IndexByte("+-:$*", dataType) < 0 {
+		return fmt.Errorf("type %q is not part of RESP2: %w", dataType, ErrUnsupportedRespDataType)
+	}
+	return nil
+}
+
+// narrowMap returns m as a map[string]interface{} if all of its keys are strings,
+// as a map[int64]interface{} if all of its keys are integers, including the int
+// keys decoded under NativeInt, and unchanged otherwise. An empty map counts as
+// string-keyed. Each map is narrowed on its own keys alone, so nested maps are
+// narrowed the same way at every depth, independently of their parents.
+func narrowMap(m map[interface{}]interface{}) interface{} {
+	allStrings, allIntegers := true, true
+	for key := range m {
+		switch key.(type) {
+		case string:
+			allIntegers = false
+		case int64, int:
+			allStrings = false
+		default:
+			allStrings, allIntegers = false, false
+		}
+	}
+
+	switch {
+	case allStrings:
+		narrowed := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			narrowed[k.(string)] = v
+		}
+		return narrowed
+
+	case allIntegers:
+		narrowed := make(map[int64]interface{}, len(m))
+		for k, v := range m {
+			if i, ok := k.(int); ok {
+				narrowed[int64(i)] = v
+				continue
+			}
+			narrowed[k.(int64)] = v
+		}
+		return narrowed
+	}
+	return m
+}
+
+// parseBigFloat parses the text of a RESP3 double into a *big.Float whose precision
+// grows with the number of digits, so long decimal values are not rounded.
+func parseBigFloat(text string) (*big.Float, error) {
+	prec := uint(len(text)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+
+	xfloat, _, err := big.ParseFloat(text, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("invalid double %q: %w: %w", text, ErrProtocol, err)
+	}
+	return xfloat, nil
+}
+
+// decodeStreamedString reads the ";<len>\r\n<data>\r\n" chunks of a streamed bulk
+// string ("$?") up to the zero-length ";0\r\n" terminator chunk and returns the
+// concatenated payload, as []byte when RawBytes is set and as string otherwise.
+func (d *Decoder) decodeStreamedString() (interface{}, error) {
+	value := []byte{}
+
+	for {
+		lengthStr, err := d.readChunkHeader()
+		if err != nil {
+			return nil, err
+		}
+
+		length, err := parseLength(lengthStr)
+		if err != nil {
+			return nil, err
+		}
+
+		if length == 0 {
+			break // Terminator chunk
+		}
+
+		if length < 0 {
+			return nil, fmt.Errorf("invalid streamed string chunk length %d: %w", length, ErrProtocol)
+		}
+
+		chunk, err := d.readPayload(length)
+		if err != nil {
+			return nil, err
+		}
+		value = append(value, chunk...)
+
+		if err := d.readCRLF(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.RawBytes {
+		return value, nil
+	}
+	return string(value), nil
+}
+
+// maxPooledBufferSize bounds the buffers kept in bufferPool, so a single large
+// payload does not pin its memory for the lifetime of the pool.
+const maxPooledBufferSize = 64 * 1024
+
+// bufferPool holds scratch buffers for payloads that are copied into a string
+// (or an error) before being returned, so they can be reused across frames.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// getBuffer returns a pooled buffer of length n. It must be given back with
+// putBuffer once its content has been copied out, and never returned to callers.
+func getBuffer(n int) *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	if cap(*buf) < n {
+		*buf = make([]byte, n)
+	}
+	*buf = (*buf)[:n]
+	return buf
+}
+
+// putBuffer returns a buffer obtained from getBuffer to the pool.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// hashableKey returns a map key or set member in a form that can be hashed:
+// the []byte payloads decoded under RawBytes become strings.
+func hashableKey(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// newOrderedMap builds an OrderedMap from the alternating keys and values of a
+// decoded map, applying the same null-key and duplicate-key rules as Go maps:
+// null keys are dropped and a repeated key keeps its first position but takes
+// the last value, unless RejectDuplicateKeys is set.
+func (d *Decoder) newOrderedMap(elements []interface{}) (OrderedMap, error) {
+	m := make(OrderedMap, 0, len(elements)/2)
+	index := make(map[interface{}]int, len(elements)/2)
+
+	for i := 0; i < len(elements); i += 2 {
+		key, value := elements[i], elements[i+1]
+
+		if key == nil {
+			continue
+		}
+
+		if reflect.TypeOf(key).Comparable() {
+			if j, exists := index[key]; exists {
+				if d.RejectDuplicateKeys {
+					return nil, fmt.Errorf("duplicate map key %v: %w", key, ErrProtocol)
+				}
+				m[j].Value = value
+				continue
+			}
+			index[key] = len(m)
+		}
+
+		m = append(m, KeyValue{Key: key, Value: value})
+	}
+
+	if d.PoolAggregates {
+		putElements(elements) // Only used as scratch space
+	}
+
+	return m, nil
+}
+
+// readCount reads the length line of an aggregate. A "?" length marks a streamed
+// aggregate whose elements are terminated by a "." frame instead of being counted.
+func (d *Decoder) readCount() (count int, streamed bool, err error) {
+	line, err := d.readLine()
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, false, io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if line == "?" {
+		return 0, true, nil
+	}
+
+	count, err = parseLength(line)
+	return count, false, err
+}
+
+// parseLength parses the length line of a string or aggregate, which must be a
+// non-negative length or -1 for null.
+func parseLength(line string) (int, error) {
+	length, err := strconv.Atoi(line)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length %q: %w: %w", line, ErrProtocol, err)
+	}
+	if length < -1 {
+		return 0, fmt.Errorf("invalid length %d: %w", length, ErrProtocol)
+	}
+	return length, nil
+}
+
+// decodeElements decodes the elements of an aggregate whose length line has already
+// been read: either count elements, or for a streamed aggregate every element up to
+// the "." terminator. It is shared by arrays, sets and maps.
+func (d *Decoder) decodeElements(count int, streamed bool) ([]interface{}, error) {
+	// The declared count is untrusted, so only a bounded amount is preallocated
+	elements := d.getElements(min(count, maxPreallocatedElements))
+
+	err := d.readElements(count, streamed, func() error {
+		element, err := d.decode()
+		if err != nil {
+			return err
+		}
+
+		elements = append(elements, element)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// readElements calls decodeNext once for each element of an aggregate whose length
+// line has already been read, leaving the decoding of the element to it. It
+// enforces MaxDepth and consumes the terminator of a streamed aggregate.
+func (d *Decoder) readElements(count int, streamed bool, decodeNext func() error) error {
+	if d.depth++; d.depth > d.maxDepth() {
+		d.depth--
+		return fmt.Errorf("aggregates nested deeper than %d levels: %w: %w", d.maxDepth(), ErrMaxDepthExceeded, ErrLimitExceeded)
+	}
+	defer func() { d.depth-- }()
+
+	for i := 0; streamed || i < count; i++ {
+		if streamed {
+			end, err := d.readStreamEnd()
+			if err != nil {
+				return err
+			}
+			if end {
+				break
+			}
+		}
+
+		err := decodeNext()
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxDepth returns the nesting limit for aggregates, see MaxDepth.
+func (d *Decoder) maxDepth() int {
+	if d.MaxDepth > 0 {
+		return d.MaxDepth
+	}
+	return defaultMaxDepth
+}
+
+// readStreamEnd consumes the ".\r\n" terminator of a streamed aggregate if it is the
+// next frame, reporting whether it was found.
+func (d *Decoder) readStreamEnd() (bool, error) {
+	next, err := d.reader.Peek(1)
+	if err == io.EOF {
+		return false, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return false, err
+	}
+	if next[0] != '.' {
+		return false, nil
+	}
+
+	if _, err := d.readByte(); err != nil {
+		return false, err
+	}
+	return true, d.readCRLF()
+}
+
+// readByte reads a single byte, copying it to the tee writer if one is set.
+func (d *Decoder) readByte() (byte, error) {
+	b, err := d.reader.ReadByte()
+	if err != nil {
+		return b, err
+	}
+
+	d.offset++
+	if d.Tee == nil {
+		return b, nil
+	}
+	return b, d.tee([]byte{b})
+}
+
+// readLine reads a CRLF-terminated line, see readLineCRLF.
+func (d *Decoder) readLine() (string, error) {
+	line, err := readLineCRLF(d.reader)
+	if err != nil {
+		return line, err
+	}
+
+	d.offset += int64(len(line)) + 2
+	if d.Tee != nil {
+		err = d.tee([]byte(line + "\r\n"))
+	}
+	return line, err
+}
+
+// readFull fills p, blocking until enough data has arrived. Running out of data
+// before p is full is reported as io.ErrUnexpectedEOF.
+func (d *Decoder) readFull(p []byte) error {
+	n, err := io.ReadFull(d.reader, p)
+	d.offset += int64(n)
+	if teeErr := d.tee(p[:n]); err == nil {
+		err = teeErr
+	}
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readPayload reads a payload of length bytes into a new slice. The declared
+// length is untrusted, so a large payload is not allocated up front: its buffer
+// grows with the data actually received instead.
+func (d *Decoder) readPayload(length int) ([]byte, error) {
+	if length <= maxPooledBufferSize {
+		value := make([]byte, length)
+		return value, d.readFull(value)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, d.reader, int64(length))
+	d.offset += n
+	if teeErr := d.tee(buf.Bytes()); err == nil {
+		err = teeErr
+	}
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+// readCRLF consumes the terminator of a length-prefixed payload, verifying that it
+// is exactly "\r\n". A mismatch means the declared length was wrong and the stream
+// is out of sync, which is reported as ErrProtocol rather than silently skipped.
+func (d *Decoder) readCRLF() error {
+	for _, expected := range []byte{'\r', '\n'} {
+		b, err := d.readByte()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if b != expected {
+			return fmt.Errorf("expected CRLF after payload, found %q: %w", b, ErrProtocol)
+		}
+	}
+	return nil
+}
+
+// tee copies consumed bytes to the Tee writer, if one is configured.
+func (d *Decoder) tee(p []byte) error {
+	if d.Tee == nil || len(p) == 0 {
+		return nil
+	}
+	_, err := d.Tee.Write(p)
+	return err
+}
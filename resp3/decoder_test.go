@@ -2,11 +2,16 @@ package resp3
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"io"
+	"math"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func newReader(input string) *bufio.Reader {
@@ -49,6 +54,34 @@ func TestDecodeError(t *testing.T) {
 	}
 }
 
+func TestDecodeErrorCode(t *testing.T) {
+	input := "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	respErr, ok := result.(*RespError)
+	if !ok {
+		t.Fatalf("expected *RespError, got %T", result)
+	}
+
+	if respErr.Code != "WRONGTYPE" {
+		t.Errorf("expected code WRONGTYPE, got %v", respErr.Code)
+	}
+
+	if respErr.Message != "Operation against a key holding the wrong kind of value" {
+		t.Errorf("unexpected message %q", respErr.Message)
+	}
+
+	if respErr.Error() != input[1:len(input)-2] {
+		t.Errorf("expected %q, got %q", input[1:len(input)-2], respErr.Error())
+	}
+}
+
 func TestDecodeInteger(t *testing.T) {
 	input := ":42\r\n"
 	expected := int64(42)
@@ -81,6 +114,54 @@ func TestDecodeNegativeInteger(t *testing.T) {
 	}
 }
 
+func TestDecodeLargeUnsignedInteger(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  interface{}
+		expectErr error
+	}{
+		{name: "Largest int64", input: ":9223372036854775807\r\n", expected: int64(math.MaxInt64)},
+		{name: "Above int64", input: ":9223372036854775808\r\n", expected: uint64(math.MaxInt64 + 1)},
+		{name: "Largest uint64", input: ":18446744073709551615\r\n", expected: uint64(math.MaxUint64)},
+		{name: "Above uint64", input: ":18446744073709551616\r\n", expectErr: ErrProtocol},
+		{name: "Below int64", input: ":-9223372036854775809\r\n", expectErr: ErrProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(newReader(tt.input))
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMaxUint64RoundTrip(t *testing.T) {
+	encoded, err := Encode(uint64(math.MaxUint64))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := Decode(newReader(encoded))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != uint64(math.MaxUint64) {
+		t.Errorf("expected %d, got %#v", uint64(math.MaxUint64), result)
+	}
+}
+
 func TestDecodeFloat(t *testing.T) {
 	input := ",3.14159\r\n"
 	expected := 3.14159
@@ -146,8 +227,8 @@ func TestDecodeEmptyBulkString(t *testing.T) {
 }
 
 func TestDecodeVerbatimString(t *testing.T) {
-	input := "=13\r\nsome verbatim\r\n"
-	expected := "some verbatim"
+	input := "=17\r\ntxt:some verbatim\r\n"
+	expected := VerbatimString{Format: "txt", Content: "some verbatim"}
 
 	reader := newReader(input)
 	result, err := Decode(reader)
@@ -161,9 +242,73 @@ func TestDecodeVerbatimString(t *testing.T) {
 	}
 }
 
+func TestDecodeVerbatimStringLargerThanBuffer(t *testing.T) {
+	content := strings.Repeat("Latency spike detected. ", 250) // 6000 bytes, over the 4096 byte buffer
+	input := "=" + strconv.Itoa(len(content)+4) + "\r\ntxt:" + content + "\r\n+next\r\n"
+
+	// Deliver the frame in small pieces, as a socket would
+	decoder := NewDecoder(iotest.HalfReader(strings.NewReader(input)))
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != (VerbatimString{Format: "txt", Content: content}) {
+		t.Errorf("expected a %d byte verbatim string, got %#v", len(content), result)
+	}
+
+	if next, err := decoder.Decode(); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+}
+
+func TestDecodeBlobErrorLargerThanBuffer(t *testing.T) {
+	message := "ERR " + strings.Repeat("stack frame\n", 500) // Over the 4096 byte buffer
+	input := "!" + strconv.Itoa(len(message)) + "\r\n" + message + "\r\n+next\r\n"
+
+	decoder := NewDecoder(iotest.HalfReader(strings.NewReader(input)))
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	respErr, ok := result.(*RespError)
+	if !ok {
+		t.Fatalf("expected *RespError, got %T", result)
+	}
+	if respErr.Code != "ERR" || respErr.Error() != message {
+		t.Errorf("expected code ERR and a %d byte message, got code %q and %d bytes", len(message), respErr.Code, len(respErr.Error()))
+	}
+
+	if next, err := decoder.Decode(); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+
+	// A wrong declared length is caught by the trailing CRLF check
+	if _, err := Decode(newReader("!3\r\nERR boom\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a wrong length, got %v", err)
+	}
+}
+
 func TestDecodeEmptyVerbatimString(t *testing.T) {
 	input := "=0\r\n\r\n"
-	expected := ""
+	expected := VerbatimString{}
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeMarkdownVerbatimString(t *testing.T) {
+	input := "=15\r\nmkd:# Heading\r\n\r\n"
+	expected := VerbatimString{Format: "mkd", Content: "# Heading\r\n"}
 
 	reader := newReader(input)
 	result, err := Decode(reader)
@@ -177,6 +322,37 @@ func TestDecodeEmptyVerbatimString(t *testing.T) {
 	}
 }
 
+func TestDecodeVerbatimStringWithoutFormat(t *testing.T) {
+	input := "=13\r\nsome verbatim\r\n"
+	expected := VerbatimString{Content: "some verbatim"}
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeNullVerbatimString(t *testing.T) {
+	reader := newReader("=-1\r\n=0\r\n\r\n")
+
+	result, err := Decode(reader)
+	if err != nil || result != nil {
+		t.Fatalf("expected nil, got %v (err %v)", result, err)
+	}
+
+	// The empty verbatim string that follows must still be aligned
+	result, err = Decode(reader)
+	if err != nil || result != (VerbatimString{}) {
+		t.Fatalf("expected empty VerbatimString, got %v (err %v)", result, err)
+	}
+}
+
 func TestDecodeArray(t *testing.T) {
 	input := "*2\r\n$3\r\nfoo\r\n$3\r\nbar\r\n"
 	expected := []interface{}{"foo", "bar"}
@@ -295,6 +471,82 @@ func TestDecodeInt64KeyMap(t *testing.T) {
 	}
 }
 
+func TestDecodeNestedMapKeyTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			name:  "Integer-key maps inside an array",
+			input: "*2\r\n%2\r\n:1\r\n+a\r\n%2\r\n+k\r\n:2\r\n",
+			expected: []interface{}{
+				map[int64]interface{}{1: "a"},
+				map[string]interface{}{"k": int64(2)},
+			},
+		},
+		{
+			name:  "Integer-key map inside a string-key map",
+			input: "%2\r\n+scores\r\n%4\r\n:1\r\n:10\r\n:2\r\n:20\r\n",
+			expected: map[string]interface{}{
+				"scores": map[int64]interface{}{1: int64(10), 2: int64(20)},
+			},
+		},
+		{
+			name:  "String-key map inside an integer-key map",
+			input: "%2\r\n:7\r\n%2\r\n+name\r\n+x\r\n",
+			expected: map[int64]interface{}{
+				7: map[string]interface{}{"name": "x"},
+			},
+		},
+		{
+			name:  "Integer-key map inside a mixed-key map",
+			input: "%4\r\n+a\r\n:1\r\n:2\r\n%2\r\n:3\r\n#t\r\n",
+			expected: map[interface{}]interface{}{
+				"a":      int64(1),
+				int64(2): map[int64]interface{}{3: true},
+			},
+		},
+		{
+			name:  "Mixed-key map inside an integer-key map",
+			input: "%2\r\n:1\r\n%4\r\n:2\r\n+b\r\n+c\r\n:3\r\n",
+			expected: map[int64]interface{}{
+				1: map[interface{}]interface{}{int64(2): "b", "c": int64(3)},
+			},
+		},
+		{
+			name:  "Deeply nested integer-key maps",
+			input: "*1\r\n%2\r\n:1\r\n*1\r\n%2\r\n:2\r\n%2\r\n:3\r\n+x\r\n",
+			expected: []interface{}{
+				map[int64]interface{}{1: []interface{}{
+					map[int64]interface{}{2: map[int64]interface{}{3: "x"}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+
+			// NativeInt must not change how maps are narrowed
+			if strconv.IntSize == 64 {
+				decoder := NewDecoder(strings.NewReader(tt.input))
+				decoder.NativeInt = true
+				if result, err := decoder.Decode(); err != nil || reflect.TypeOf(result) != reflect.TypeOf(tt.expected) {
+					t.Errorf("expected %T with NativeInt, got %T (err %v)", tt.expected, result, err)
+				}
+			}
+		})
+	}
+}
+
 func TestDecodeMixedKeyMap(t *testing.T) {
 	input := "%6\r\n+key1\r\n$6\r\nvalue1\r\n:2\r\n$6\r\nvalue2\r\n+key3\r\n$6\r\nvalue3\r\n"
 	expected := map[interface{}]interface{}{
@@ -335,6 +587,26 @@ func TestDecodeBlobError(t *testing.T) {
 	}
 }
 
+func TestDecodeBlobErrorCode(t *testing.T) {
+	input := "!21\r\nSYNTAX invalid syntax\r\n"
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var respErr *RespError
+	if !errors.As(result.(error), &respErr) {
+		t.Fatalf("expected *RespError, got %T", result)
+	}
+
+	if respErr.Code != "SYNTAX" || respErr.Message != "invalid syntax" {
+		t.Errorf("unexpected error fields: %+v", respErr)
+	}
+}
+
 func TestDecodeNull(t *testing.T) {
 	input := "_\r\n"
 	var expected interface{} = nil
@@ -351,6 +623,88 @@ func TestDecodeNull(t *testing.T) {
 	}
 }
 
+func TestDecodePush(t *testing.T) {
+	result, err := Decode(newReader(">2\r\n+invalidate\r\n*1\r\n$3\r\nkey\r\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := PushMessage{"invalidate", []interface{}{"key"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+
+	if _, err := Decode(newReader(">-1\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a null push, got %v", err)
+	}
+}
+
+func TestDecodeAttributed(t *testing.T) {
+	reader := newReader("*2\r\n|2\r\n+ttl\r\n:60\r\n+v\r\n:2\r\n+next\r\n")
+	result, err := Decode(reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{Attributed{Attributes: map[string]interface{}{"ttl": int64(60)}, Value: "v"}, int64(2)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+	if next, err := Decode(reader); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+
+	for _, input := range []string{"|-1\r\n+v\r\n", "|1\r\n+ttl\r\n+v\r\n", "|2\r\n:1\r\n:60\r\n+v\r\n"} {
+		if _, err := Decode(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("expected ErrProtocol for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestIsStatus(t *testing.T) {
+	input := "+OK\r\n+QUEUED\r\n$2\r\nOK\r\n*2\r\n+PONG\r\n$4\r\nPONG\r\n"
+
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.SimpleStrings = true
+
+	var statuses []string
+	for {
+		reply, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		elements, ok := reply.([]interface{})
+		if !ok {
+			elements = []interface{}{reply}
+		}
+		for _, element := range elements {
+			status, ok := IsStatus(element)
+			if ok {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	expected := []string{"OK", "QUEUED", "PONG"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected statuses %v, got %v", expected, statuses)
+	}
+
+	if status, ok := IsStatus(Value{Type: TypeSimpleString, Str: "OK"}); !ok || status != "OK" {
+		t.Errorf("expected a simple string Value to be a status, got %q, %t", status, ok)
+	}
+	if _, ok := IsStatus(Value{Type: TypeBulkString, Str: "OK"}); ok {
+		t.Errorf("expected a bulk string Value not to be a status")
+	}
+	if _, ok := IsStatus("OK"); ok {
+		t.Errorf("expected a plain string not to be a status")
+	}
+}
+
 func TestDecodeUnsupportedType(t *testing.T) {
 	input := "&\r\n"
 
@@ -462,3 +816,991 @@ func TestDecodeMapWithMissingValue(t *testing.T) {
 		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
 	}
 }
+
+func TestDecoderTee(t *testing.T) {
+	input := "*3\r\n$3\r\nfoo\r\n%2\r\n+key\r\n*2\r\n:1\r\n#t\r\n!9\r\nERR oops!\r\n"
+
+	var captured bytes.Buffer
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.Tee = &captured
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if captured.String() != input {
+		t.Errorf("expected tee to capture %q, got %q", input, captured.String())
+	}
+
+	array, ok := result.([]interface{})
+	if !ok || len(array) != 3 {
+		t.Fatalf("expected 3-element array, got %v", result)
+	}
+
+	expectedMap := map[string]interface{}{"key": []interface{}{int64(1), true}}
+	if !reflect.DeepEqual(array[1], expectedMap) {
+		t.Errorf("expected %v, got %v", expectedMap, array[1])
+	}
+}
+
+func TestDecodeInvalidTrailingCRLF(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Bulk string shorter than declared",
+			input: "$3\r\nfoobar\r\n",
+		},
+		{
+			name:  "Verbatim string shorter than declared",
+			input: "=5\r\ntxt:hello\r\n",
+		},
+		{
+			name:  "Blob error shorter than declared",
+			input: "!3\r\nERR failure\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(newReader(tt.input))
+			if !errors.Is(err, ErrProtocol) {
+				t.Fatalf("expected ErrProtocol, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecoderNativeInt(t *testing.T) {
+	if strconv.IntSize != 64 {
+		t.Skip("test expects a 64-bit platform")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{
+			name:     "Small integer",
+			input:    ":42\r\n",
+			expected: 42,
+		},
+		{
+			name:     "Large integer",
+			input:    ":9223372036854775807\r\n",
+			expected: math.MaxInt64,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.NativeInt = true
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected int %v, got %T %v", tt.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestDecodeSet(t *testing.T) {
+	input := "~3\r\n+a\r\n:1\r\n+a\r\n"
+	expected := Set{"a": {}, int64(1): {}}
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecoderRESP2Only(t *testing.T) {
+	for _, input := range []string{"%0\r\n", "#t\r\n", ",1.5\r\n", "_\r\n", "~0\r\n", ">0\r\n", "|0\r\n", "(1\r\n", "*1\r\n#t\r\n"} {
+		decoder := NewDecoder(strings.NewReader(input))
+		decoder.RESP2Only = true
+
+		if _, err := decoder.Decode(); !errors.Is(err, ErrUnsupportedRespDataType) {
+			t.Errorf("Decode(%q) error = %v, want ErrUnsupportedRespDataType", input, err)
+		}
+	}
+
+	decoder := NewDecoder(strings.NewReader("*3\r\n+OK\r\n:1\r\n$-1\r\n"))
+	decoder.RESP2Only = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{"OK", int64(1), nil}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecoderAllowInline(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("PING\r\nSET foo  bar\r\n*1\r\n$4\r\nPING\r\n\r\n"))
+	decoder.AllowInline = true
+
+	expected := []interface{}{
+		[]string{"PING"},
+		[]string{"SET", "foo", "bar"},
+		[]interface{}{"PING"},
+		[]string{},
+	}
+
+	for _, want := range expected {
+		result, err := decoder.Decode()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("expected %#v, got %#v", want, result)
+		}
+	}
+
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	if _, err := Decode(newReader("PING\r\n")); !errors.Is(err, ErrUnsupportedRespDataType) {
+		t.Errorf("expected ErrUnsupportedRespDataType without AllowInline, got %v", err)
+	}
+}
+
+func TestDecoderSimpleStrings(t *testing.T) {
+	input := "*2\r\n+OK\r\n$2\r\nOK\r\n"
+
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.SimpleStrings = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{SimpleString("OK"), "OK"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, result)
+	}
+
+	encoded, err := Encode(result)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if encoded != "*2\r\n+OK\r\n+OK\r\n" {
+		t.Errorf("expected %q, got %q", "*2\r\n+OK\r\n+OK\r\n", encoded)
+	}
+}
+
+func TestDecoderErrorsAsError(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("-ERR boom\r\n*1\r\n-WRONGTYPE nested\r\n"))
+	decoder.ErrorsAsError = true
+
+	result, err := decoder.Decode()
+	if result != nil {
+		t.Errorf("expected nil value, got %v", result)
+	}
+
+	var respErr *RespError
+	if !errors.As(err, &respErr) || respErr.Code != "ERR" {
+		t.Fatalf("expected ERR error, got %v", err)
+	}
+
+	result, err = decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error for a nested error reply, got %v", err)
+	}
+
+	expected := []interface{}{&RespError{Code: "WRONGTYPE", Message: "nested"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecoderOrderedSets(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("~4\r\n+c\r\n:1\r\n+a\r\n+b\r\n"))
+	decoder.OrderedSets = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{"c", int64(1), "a", "b"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecoderPreserveOrder(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("%8\r\n+maxmemory\r\n:0\r\n+appendonly\r\n+no\r\n:1\r\n+one\r\n+maxmemory\r\n:100\r\n"))
+	decoder.PreserveOrder = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := OrderedMap{
+		{Key: "maxmemory", Value: int64(100)},
+		{Key: "appendonly", Value: "no"},
+		{Key: int64(1), Value: "one"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+
+	if value, ok := expected.Get("appendonly"); !ok || value != "no" {
+		t.Errorf("Get(appendonly) = %v, %v, want no, true", value, ok)
+	}
+	if _, ok := expected.Get("missing"); ok {
+		t.Errorf("Get(missing) reported a value")
+	}
+}
+
+func TestDecodeStreamedAggregates(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			name:     "Streamed array",
+			input:    "*?\r\n:1\r\n+two\r\n#t\r\n.\r\n",
+			expected: []interface{}{int64(1), "two", true},
+		},
+		{
+			name:     "Empty streamed array",
+			input:    "*?\r\n.\r\n",
+			expected: []interface{}{},
+		},
+		{
+			name:     "Nested streamed array",
+			input:    "*?\r\n*?\r\n:1\r\n.\r\n*1\r\n:2\r\n.\r\n",
+			expected: []interface{}{[]interface{}{int64(1)}, []interface{}{int64(2)}},
+		},
+		{
+			name:     "Streamed set",
+			input:    "~?\r\n+a\r\n+b\r\n.\r\n",
+			expected: Set{"a": {}, "b": {}},
+		},
+		{
+			name:     "Streamed map",
+			input:    "%?\r\n+key1\r\n:1\r\n+key2\r\n:2\r\n.\r\n",
+			expected: map[string]interface{}{"key1": int64(1), "key2": int64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeIncompleteStreamedArray(t *testing.T) {
+	input := "*?\r\n:1\r\n:2\r\n" // Missing the "." terminator
+
+	reader := newReader(input)
+	_, err := Decode(reader)
+
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestDecodeStreamedMapMissingValue(t *testing.T) {
+	input := "%?\r\n+key1\r\n:1\r\n+key2\r\n.\r\n"
+
+	reader := newReader(input)
+	_, err := Decode(reader)
+
+	if !errors.Is(err, ErrProtocol) {
+		t.Fatalf("expected ErrProtocol, got %v", err)
+	}
+}
+
+func TestDecoderRawBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []byte
+	}{
+		{
+			name:     "Bulk string",
+			input:    "$6\r\nfoo\x00ba\r\n",
+			expected: []byte("foo\x00ba"),
+		},
+		{
+			name:     "Verbatim string",
+			input:    "=15\r\ntxt:Some string\r\n",
+			expected: []byte("Some string"),
+		},
+		{
+			name:     "Empty verbatim string",
+			input:    "=0\r\n\r\n",
+			expected: []byte{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.RawBytes = true
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %q, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecoderRawBytesKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			name:     "Map with bulk string keys",
+			input:    "%4\r\n$3\r\nfoo\r\n$3\r\nbar\r\n$1\r\nn\r\n:1\r\n",
+			expected: map[string]interface{}{"foo": []byte("bar"), "n": int64(1)},
+		},
+		{
+			name:     "Set of bulk strings",
+			input:    "~2\r\n$1\r\na\r\n$1\r\nb\r\n",
+			expected: Set{"a": {}, "b": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.RawBytes = true
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeStreamedBulkString(t *testing.T) {
+	input := "$?\r\n;4\r\nHell\r\n;5\r\no wor\r\n;1\r\nd\r\n;0\r\n"
+	expected := "Hello word"
+
+	reader := newReader(input)
+	result, err := Decode(reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeStreamedBulkStringRawBytes(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("$?\r\n;3\r\nfoo\r\n;3\r\nbar\r\n;0\r\n"))
+	decoder.RawBytes = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(result, []byte("foobar")) {
+		t.Errorf("expected %q, got %#v", "foobar", result)
+	}
+}
+
+func TestDecodeStreamedBulkStringErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{
+			name:     "Missing terminator chunk",
+			input:    "$?\r\n;3\r\nfoo\r\n",
+			expected: io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "Invalid chunk marker",
+			input:    "$?\r\n:3\r\n",
+			expected: ErrProtocol,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(newReader(tt.input))
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDecoderRawBytesNotPooled(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("$3\r\nfoo\r\n$3\r\nbar\r\n"))
+	decoder.RawBytes = true
+
+	first, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(first.([]byte)) != "foo" {
+		t.Errorf("expected first value to stay %q, got %q", "foo", first)
+	}
+}
+
+func BenchmarkDecodeBulkString(b *testing.B) {
+	input := "*3\r\n$64\r\n" + strings.Repeat("x", 64) + "\r\n=68\r\ntxt:" + strings.Repeat("y", 64) +
+		"\r\n!64\r\nERR " + strings.Repeat("z", 60) + "\r\n"
+	source := strings.NewReader(input)
+	reader := bufio.NewReader(source)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		source.Reset(input)
+		reader.Reset(source)
+		if _, err := Decode(reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecoderUniformMapType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[interface{}]interface{}
+	}{
+		{
+			name:     "String keys",
+			input:    "%4\r\n+key1\r\n$6\r\nvalue1\r\n+key2\r\n$6\r\nvalue2\r\n",
+			expected: map[interface{}]interface{}{"key1": "value1", "key2": "value2"},
+		},
+		{
+			name:     "Int64 keys",
+			input:    "%2\r\n:1\r\n$6\r\nvalue1\r\n",
+			expected: map[interface{}]interface{}{int64(1): "value1"},
+		},
+		{
+			name:     "Empty map",
+			input:    "%0\r\n",
+			expected: map[interface{}]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.UniformMapType = true
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecoderRejectDuplicateKeys(t *testing.T) {
+	input := "%4\r\n+key\r\n:1\r\n+key\r\n:2\r\n"
+
+	result, err := Decode(newReader(input))
+	if err != nil {
+		t.Fatalf("expected no error by default, got %v", err)
+	}
+
+	expected := map[string]interface{}{"key": int64(2)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected last value to win %v, got %v", expected, result)
+	}
+
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.RejectDuplicateKeys = true
+
+	if _, err := decoder.Decode(); !errors.Is(err, ErrProtocol) {
+		t.Fatalf("expected ErrProtocol, got %v", err)
+	}
+}
+
+func TestPeekType(t *testing.T) {
+	reader := newReader(">2\r\n+message\r\n+hello\r\n")
+
+	dataType, err := PeekType(reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if dataType != '>' {
+		t.Errorf("expected '>', got %q", dataType)
+	}
+
+	if reader.Buffered() != 22 {
+		t.Errorf("expected nothing to be consumed, %d bytes buffered", reader.Buffered())
+	}
+}
+
+func TestPeekTypeEOF(t *testing.T) {
+	if _, err := PeekType(newReader("")); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecodeErrorContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedType   byte
+		expectedOffset int64
+	}{
+		{
+			name:           "Invalid integer",
+			input:          ":xyz\r\n",
+			expectedType:   ':',
+			expectedOffset: 6,
+		},
+		{
+			name:           "Invalid element inside array",
+			input:          "*2\r\n+ok\r\n,abc\r\n",
+			expectedType:   ',',
+			expectedOffset: 15,
+		},
+		{
+			name:           "Unsupported type marker",
+			input:          "*1\r\n&\r\n",
+			expectedType:   '&',
+			expectedOffset: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(newReader(tt.input))
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected *DecodeError, got %v", err)
+			}
+
+			if decodeErr.Type != tt.expectedType {
+				t.Errorf("expected type %q, got %q", tt.expectedType, decodeErr.Type)
+			}
+
+			if decodeErr.Offset != tt.expectedOffset {
+				t.Errorf("expected offset %d, got %d", tt.expectedOffset, decodeErr.Offset)
+			}
+		})
+	}
+}
+
+func TestDecoderOffsetAcrossFrames(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("+OK\r\n:1\r\n:oops\r\n"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := decoder.Decode(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	_, err := decoder.Decode()
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Offset != 16 {
+		t.Fatalf("expected *DecodeError at offset 16, got %v", err)
+	}
+}
+
+func TestDecoderUseBigFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Decimal fraction",
+			input:    ",0.1\r\n",
+			expected: "0.1",
+		},
+		{
+			name:     "More digits than float64 holds",
+			input:    ",12345678901234567890.123456789\r\n",
+			expected: "1.2345678901234567890123456789e+19",
+		},
+		{
+			name:     "Negative exponent",
+			input:    ",-1.5e-10\r\n",
+			expected: "-1.5e-10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.UseBigFloat = true
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			bigFloat, ok := result.(*big.Float)
+			if !ok {
+				t.Fatalf("expected *big.Float, got %T", result)
+			}
+
+			if bigFloat.Text('g', -1) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, bigFloat.Text('g', -1))
+			}
+		})
+	}
+}
+
+func TestDecodeBooleanValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  interface{}
+		expectErr error
+	}{
+		{name: "True", input: "#t\r\n", expected: true},
+		{name: "False", input: "#f\r\n", expected: false},
+		{name: "Malformed value", input: "#q\r\n", expectErr: ErrProtocol},
+		{name: "Missing CRLF", input: "#tt\r\n", expectErr: ErrProtocol},
+		{name: "Truncated", input: "#t", expectErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(newReader(tt.input))
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeMapInvalidHeader(t *testing.T) {
+	if _, err := Decode(newReader("%2")); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for truncated header, got %v", err)
+	}
+
+	_, err := Decode(newReader("%x\r\n"))
+
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("expected a parse error for non-numeric size, got %v", err)
+	}
+}
+
+func TestDecoderLastFrameSize(t *testing.T) {
+	frames := []string{
+		"+OK\r\n",
+		"$6\r\nfoobar\r\n",
+		"*2\r\n:1\r\n%2\r\n+k\r\n#t\r\n",
+		"$?\r\n;2\r\nab\r\n;0\r\n",
+		"_\r\n",
+	}
+
+	decoder := NewDecoder(strings.NewReader(strings.Join(frames, "")))
+
+	for _, frame := range frames {
+		if _, err := decoder.Decode(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if decoder.LastFrameSize() != len(frame) {
+			t.Errorf("expected size %d for %q, got %d", len(frame), frame, decoder.LastFrameSize())
+		}
+	}
+}
+
+func TestDecodeNullMap(t *testing.T) {
+	reader := newReader("%-1\r\n+next\r\n")
+
+	result, err := Decode(reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("expected nil, got %v", result)
+	}
+
+	// The frame following the null map must still be aligned
+	if next, err := Decode(reader); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+}
+
+func TestDecodeMalformedInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expectErr error
+	}{
+		{name: "Negative bulk length", input: "$-5\r\nabc\r\n", expectErr: ErrProtocol},
+		{name: "Bulk length -2", input: "$-2\r\n", expectErr: ErrProtocol},
+		{name: "Negative blob error length", input: "!-2\r\n", expectErr: ErrProtocol},
+		{name: "Overflowing bulk length", input: "$9223372036854775807\r\nabc\r\n", expectErr: io.ErrUnexpectedEOF},
+		{name: "Negative verbatim length", input: "=-2\r\ntxt:a\r\n", expectErr: ErrProtocol},
+		{name: "Null blob error", input: "!-1\r\n", expectErr: ErrProtocol},
+		{name: "Negative array count", input: "*-2\r\n", expectErr: ErrProtocol},
+		{name: "Negative map count", input: "%-4\r\n", expectErr: ErrProtocol},
+		{name: "Map count -3", input: "%-3\r\n", expectErr: ErrProtocol},
+		{name: "Array count -5", input: "*-5\r\n", expectErr: ErrProtocol},
+		{name: "Negative set count", input: "~-2\r\n", expectErr: ErrProtocol},
+		{name: "Nested negative count", input: "*1\r\n*-2\r\n", expectErr: ErrProtocol},
+		{name: "Huge array count", input: "*9223372036854775807\r\n:1\r\n", expectErr: io.ErrUnexpectedEOF},
+		{name: "Invalid integer", input: ":12a\r\n", expectErr: ErrProtocol},
+		{name: "Invalid double", input: ",1.2.3\r\n", expectErr: ErrProtocol},
+		{name: "Null without CRLF", input: "_xy", expectErr: ErrProtocol},
+		{name: "Unhashable map key", input: "%2\r\n*0\r\n:1\r\n", expectErr: ErrProtocol},
+		{name: "Too deeply nested", input: strings.Repeat("*1\r\n", 1000) + ":1\r\n", expectErr: ErrLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(newReader(tt.input))
+			if !errors.Is(err, tt.expectErr) {
+				t.Errorf("expected %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestDecoderMaxDepth(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("*1\r\n*1\r\n*0\r\n*1\r\n*1\r\n*1\r\n*0\r\n"))
+	decoder.MaxDepth = 3
+
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("expected no error at depth 3, got %v", err)
+	}
+	_, err := decoder.Decode()
+	if !errors.Is(err, ErrMaxDepthExceeded) || !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrMaxDepthExceeded and ErrLimitExceeded at depth 4, got %v", err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range []string{
+		"+OK\r\n",
+		"-ERR boom\r\n",
+		":42\r\n",
+		",3.14\r\n",
+		"#t\r\n",
+		"_\r\n",
+		"$5\r\nhello\r\n",
+		"=8\r\ntxt:hey!\r\n",
+		"!5\r\nERR x\r\n",
+		"*2\r\n:1\r\n$1\r\na\r\n",
+		"~2\r\n+a\r\n+b\r\n",
+		"%2\r\n+k\r\n:1\r\n",
+		"*?\r\n:1\r\n.\r\n",
+		"$?\r\n;2\r\nab\r\n;0\r\n",
+		"$-1\r\n*-1\r\n%-1\r\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoder := NewDecoder(bytes.NewReader(data))
+
+		consumed := 0
+		for {
+			_, err := decoder.Decode()
+
+			consumed += decoder.LastFrameSize()
+			if consumed > len(data) {
+				t.Fatalf("consumed %d bytes of a %d byte input", consumed, len(data))
+			}
+
+			if err == nil {
+				continue
+			}
+			if err == io.EOF {
+				return
+			}
+
+			if !errors.Is(err, ErrProtocol) && !errors.Is(err, ErrLimitExceeded) &&
+				!errors.Is(err, ErrUnsupportedRespDataType) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("unexpected error for %q: %v", data, err)
+			}
+			return
+		}
+	})
+}
+
+func TestDecoderTricklingInput(t *testing.T) {
+	input := "*3\r\n$20\r\na bulk string of 20 \r\n%2\r\n+k\r\n:1\r\n$?\r\n;3\r\nabc\r\n;0\r\n"
+
+	// Every read returns a single byte, as a slow connection might
+	decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{"a bulk string of 20 ", map[string]interface{}{"k": int64(1)}, "abc"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+func TestDecoderWaitsForMoreData(t *testing.T) {
+	reader, writer := io.Pipe()
+	go func() {
+		for _, piece := range []string{"*2\r\n", "$5\r\nhel", "lo\r\n", ":4", "2\r\n"} {
+			writer.Write([]byte(piece))
+		}
+		writer.Close()
+	}()
+
+	result, err := NewDecoder(reader).Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{"hello", int64(42)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeLargePayloads(t *testing.T) {
+	payload := strings.Repeat("x", 1<<20)
+
+	bulk := "$" + strconv.Itoa(len(payload)) + "\r\n" + payload + "\r\n"
+	result, err := NewDecoder(strings.NewReader(bulk)).Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != payload {
+		t.Errorf("expected a %d byte bulk string, got %d bytes", len(payload), len(result.(string)))
+	}
+
+	verbatim := "=" + strconv.Itoa(len(payload)+4) + "\r\ntxt:" + payload + "\r\n"
+	result, err = NewDecoder(strings.NewReader(verbatim)).Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != (VerbatimString{Format: "txt", Content: payload}) {
+		t.Errorf("expected a %d byte verbatim string", len(payload))
+	}
+}
+
+func TestDecoderSynced(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		decode func(d *Decoder) error
+		synced bool
+	}{
+		{"clean EOF", "", func(d *Decoder) error { _, err := d.Decode(); return err }, true},
+		{"error reply as error", "-ERR boom\r\n", func(d *Decoder) error {
+			d.ErrorsAsError = true
+			_, err := d.Decode()
+			return err
+		}, true},
+		{"wrong type for DecodeInt", "#t\r\n", func(d *Decoder) error { _, err := d.DecodeInt(); return err }, true},
+		{"wrong type for DecodeBulkTo", "*1\r\n:1\r\n", func(d *Decoder) error { _, err := d.DecodeBulkTo(io.Discard); return err }, true},
+		{"non-string element", "*2\r\n:1\r\n+a\r\n", func(d *Decoder) error { _, err := d.DecodeStringSlice(); return err }, true},
+		{"malformed integer", "*2\r\n:x\r\n:2\r\n", func(d *Decoder) error { _, err := d.Decode(); return err }, false},
+		{"truncated frame", "$5\r\nab", func(d *Decoder) error { _, err := d.Decode(); return err }, false},
+		{"depth exceeded", "*1\r\n*1\r\n:1\r\n", func(d *Decoder) error {
+			d.MaxDepth = 1
+			_, err := d.Decode()
+			return err
+		}, false},
+		{"streamed header", "*?\r\n:1\r\n.\r\n", func(d *Decoder) error { _, _, err := d.ReadArrayHeader(); return err }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(newReader(tt.input))
+			if err := tt.decode(decoder); err == nil {
+				t.Fatalf("expected error, got none")
+			}
+			if decoder.Synced() != tt.synced {
+				t.Errorf("expected Synced() = %t, got %t", tt.synced, decoder.Synced())
+			}
+		})
+	}
+}
+
+func TestDecoderSyncedStaysFalse(t *testing.T) {
+	decoder := NewDecoder(newReader("*2\r\n:x\r\n:2\r\n+OK\r\n"))
+	if _, err := decoder.Decode(); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+
+	// Later decodes may succeed on leftover bytes, but the stream stays untrusted
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoder.Synced() {
+		t.Errorf("expected Synced() = false after a failed frame")
+	}
+}
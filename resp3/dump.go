@@ -0,0 +1,126 @@
+package resp3
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Dump renders a decoded RESP3 value as indented, human-readable text with the
+// type of every value annotated, for use while debugging. Aggregates span one
+// line per element; map entries and set elements are listed in the order used
+// by EncodeSorted so that equal values always dump identically.
+//
+// Parameters:
+//   - v interface{}: A value returned by Decode, or any other Go value.
+//
+// Returns:
+//   - string: The rendered text, without a trailing newline.
+//
+// Example usage:
+//
+//	value, _ := Decode(reader) // "%2\r\n+a\r\n~1\r\n:1\r\n+b\r\n-ERR boom\r\n"
+//	fmt.Println(Dump(value))
+//	// (map) {
+//	//   (string) "a" => (set) {
+//	//     (integer) 1
+//	//   }
+//	//   (string) "b" => (error) ERR boom
+//	// }
+func Dump(v interface{}) string {
+	var sb strings.Builder
+	dumpValue(&sb, v, "")
+	return sb.String()
+}
+
+// dumpValue writes the rendering of v to sb, indenting the lines of nested
+// elements by indent plus two spaces.
+func dumpValue(sb *strings.Builder, v interface{}, indent string) {
+	switch value := v.(type) {
+	case nil:
+		sb.WriteString("(null)")
+	case string:
+		fmt.Fprintf(sb, "(string) %q", value)
+	case SimpleString:
+		fmt.Fprintf(sb, "(simple string) %s", string(value))
+	case []byte:
+		fmt.Fprintf(sb, "(bytes) %q", value)
+	case VerbatimString:
+		fmt.Fprintf(sb, "(verbatim %s) %q", value.Format, value.Content)
+	case int64, uint64:
+		fmt.Fprintf(sb, "(integer) %d", value)
+	case float64:
+		sb.WriteString("(double) " + formatDouble(value, 64))
+	case *big.Float:
+		sb.WriteString("(double) " + formatBigFloat(value))
+	case *big.Int:
+		sb.WriteString("(big number) " + value.String())
+	case bool:
+		fmt.Fprintf(sb, "(boolean) %t", value)
+	case *RespError:
+		sb.WriteString("(error) " + value.Error())
+
+	case []interface{}:
+		dumpElements(sb, "(array) [", "]", indent, len(value), func(i int) { dumpValue(sb, value[i], indent+"  ") })
+	case []string:
+		dumpElements(sb, "(array) [", "]", indent, len(value), func(i int) { dumpValue(sb, value[i], indent+"  ") })
+	case PushMessage:
+		dumpElements(sb, "(push) [", "]", indent, len(value), func(i int) { dumpValue(sb, value[i], indent+"  ") })
+
+	case Set:
+		elements := make([]interface{}, 0, len(value))
+		for elem := range value {
+			elements = append(elements, elem)
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			return compareKeys(elements[i], elements[j]) < 0
+		})
+		dumpElements(sb, "(set) {", "}", indent, len(elements), func(i int) { dumpValue(sb, elements[i], indent+"  ") })
+
+	case OrderedMap:
+		dumpElements(sb, "(map) {", "}", indent, len(value), func(i int) { dumpEntry(sb, value[i].Key, value[i].Value, indent+"  ") })
+
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Map {
+			fmt.Fprintf(sb, "(%T) %v", v, v)
+			return
+		}
+
+		// Maps of any key type, such as map[string]interface{} or map[int64]interface{}
+		entries := make([]mapEntry, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries = append(entries, mapEntry{key: iter.Key().Interface(), value: iter.Value().Interface()})
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return compareKeys(entries[i].key, entries[j].key) < 0
+		})
+		dumpElements(sb, "(map) {", "}", indent, len(entries), func(i int) { dumpEntry(sb, entries[i].key, entries[i].value, indent+"  ") })
+	}
+}
+
+// dumpElements writes an aggregate of count elements between open and close,
+// one element per line as written by dumpElement. Empty aggregates stay on one line.
+func dumpElements(sb *strings.Builder, open, close, indent string, count int, dumpElement func(i int)) {
+	sb.WriteString(open)
+	if count == 0 {
+		sb.WriteString(close)
+		return
+	}
+
+	for i := 0; i < count; i++ {
+		sb.WriteString("\n" + indent + "  ")
+		dumpElement(i)
+	}
+	sb.WriteString("\n" + indent + close)
+}
+
+// dumpEntry writes a single map entry as "key => value".
+func dumpEntry(sb *strings.Builder, key, value interface{}, indent string) {
+	dumpValue(sb, key, indent)
+	sb.WriteString(" => ")
+	dumpValue(sb, value, indent)
+}
@@ -0,0 +1,83 @@
+package resp3
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "String", input: "$5\r\nhello\r\n", expected: `(string) "hello"`},
+		{name: "Integer", input: ":-42\r\n", expected: "(integer) -42"},
+		{name: "Double", input: ",1.5\r\n", expected: "(double) 1.500000"},
+		{name: "Boolean", input: "#t\r\n", expected: "(boolean) true"},
+		{name: "Null", input: "_\r\n", expected: "(null)"},
+		{name: "Error", input: "-WRONGTYPE Operation against a key\r\n", expected: "(error) WRONGTYPE Operation against a key"},
+		{name: "Verbatim string", input: "=6\r\nmkd:hi\r\n", expected: `(verbatim mkd) "hi"`},
+		{name: "Empty array", input: "*0\r\n", expected: "(array) []"},
+		{
+			name:     "Array",
+			input:    "*2\r\n:1\r\n*1\r\n+a\r\n",
+			expected: "(array) [\n  (integer) 1\n  (array) [\n    (string) \"a\"\n  ]\n]",
+		},
+		{
+			name:     "Set",
+			input:    "~3\r\n:2\r\n+b\r\n:1\r\n",
+			expected: "(set) {\n  (string) \"b\"\n  (integer) 1\n  (integer) 2\n}",
+		},
+		{
+			name:     "Map",
+			input:    "%4\r\n+b\r\n-ERR boom\r\n+a\r\n~1\r\n:1\r\n",
+			expected: "(map) {\n  (string) \"a\" => (set) {\n    (integer) 1\n  }\n  (string) \"b\" => (error) ERR boom\n}",
+		},
+		{
+			name:     "Mixed-key map",
+			input:    "%4\r\n:2\r\n#f\r\n+k\r\n_\r\n",
+			expected: "(map) {\n  (string) \"k\" => (null)\n  (integer) 2 => (boolean) false\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result := Dump(value); result != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDumpGoValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Simple string", input: SimpleString("OK"), expected: "(simple string) OK"},
+		{name: "Bytes", input: []byte("a\x00"), expected: `(bytes) "a\x00"`},
+		{name: "Big number", input: big.NewInt(-7), expected: "(big number) -7"},
+		{name: "Big float", input: big.NewFloat(0.5), expected: "(double) 0.5"},
+		{
+			name:     "Ordered map",
+			input:    OrderedMap{{Key: "z", Value: int64(1)}, {Key: "a", Value: []string{}}},
+			expected: "(map) {\n  (string) \"z\" => (integer) 1\n  (string) \"a\" => (array) []\n}",
+		},
+		{name: "Push", input: PushMessage{"message", "hi"}, expected: "(push) [\n  (string) \"message\"\n  (string) \"hi\"\n]"},
+		{name: "Other type", input: 3, expected: "(int) 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Dump(tt.input); result != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
@@ -1,9 +1,16 @@
 package resp3
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,41 +20,127 @@ import (
 //
 // Supported Types:
 //
-//   - **String**: Encodes Go strings as RESP3 bulk strings.
-//     Example: "hello" -> "$5\r\nhello\r\n"
+//   - **String**: Encodes Go strings of up to 16 bytes as RESP3 simple strings and longer ones
+//     as bulk strings. The same boundary applies wherever a string appears, standalone or as
+//     an element of a slice or map value, and an Encoder can change it through its
+//     SimpleStringMaxLen option. Strings containing CR or LF are always bulk strings.
+//     Example: "hello" -> "+hello\r\n", "a somewhat longer value" -> "$23\r\na somewhat longer value\r\n"
+//
+//   - **SimpleString**: Encodes SimpleString values as RESP3 simple strings regardless of length.
+//     They must not contain CR or LF.
+//     Example: SimpleString("OK") -> "+OK\r\n"
+//
+//   - **json.RawMessage**: Encodes raw JSON as a RESP3 bulk string holding the raw bytes, also
+//     when nested in slices or maps such as []json.RawMessage or map[string]json.RawMessage.
+//     Example: json.RawMessage(`{"a":1}`) -> "$7\r\n{\"a\":1}\r\n"
+//
+//   - **json.Number**: Encodes JSON numbers as RESP3 integers when they hold an integer that fits
+//     in an int64 or uint64, and as RESP3 floating-point numbers otherwise.
+//     Example: json.Number("42") -> ":42\r\n", json.Number("1.5") -> ",1.500000\r\n"
+//
+//   - **VerbatimString**: Encodes VerbatimString values as RESP3 verbatim strings. The Format
+//     must be exactly three bytes.
+//     Example: VerbatimString{Format: "txt", Content: "hi"} -> "=6\r\ntxt:hi\r\n"
 //
 //   - **Integers**: Supports all Go integer types (int, int8, int16, int32, int64, uint, uint8, etc.)
-//     and encodes them as RESP3 integers.
-//     Example: 123 -> ":123\r\n"
+//     and encodes them as RESP3 integers. This includes rune (int32) and byte (uint8) values,
+//     which are encoded as their numeric code; convert with string(r) to send a character.
+//     Only []byte, as binary data, is encoded as a string: []rune is an array of integers.
+//     Example: 123 -> ":123\r\n", 'A' -> ":65\r\n", string('A') -> "+A\r\n"
+//
+//   - **Floats**: Encodes float32 and float64 types as RESP3 floating-point numbers, with six
+//     decimals when that represents the value exactly and in the shortest exact form otherwise,
+//     so no precision is lost. Infinities and NaN are written as inf, -inf and nan.
+//     Example: 3.14 -> ",3.140000\r\n", 1e-9 -> ",1e-09\r\n", math.Inf(1) -> ",inf\r\n"
 //
-//   - **Floats**: Encodes float32 and float64 types as RESP3 floating-point numbers.
-//     Example: 3.14 -> ",3.140000\r\n"
+//   - **Complex numbers**: RESP3 has no complex type, so complex64 and complex128 values are
+//     encoded as two-element arrays holding the real and then the imaginary part as doubles,
+//     written like floats. DecodeComplex reads them back.
+//     Example: complex(1.5, -2) -> "*2\r\n,1.500000\r\n,-2.000000\r\n"
+//
+//   - **big.Float**: Encodes *big.Float values as RESP3 doubles written with every digit of their
+//     precision, so arbitrary-precision decimals survive the trip; a nil pointer encodes as null.
+//     Decoding them back requires a Decoder with UseBigFloat set.
+//     Example: big.NewFloat(0.5) -> ",0.5\r\n"
 //
 //   - **Booleans**: Encodes booleans (true/false) as RESP3 boolean values.
 //     Example: true -> "#t\r\n", false -> "#f\r\n"
 //
-//   - **Nil**: Encodes nil as RESP3 null.
-//     Example: nil -> "_\r\n"
+//   - **Nil**: Encodes nil, as well as nil slices and nil maps of any type, as RESP3 null.
+//     Non-nil empty slices and maps still encode as empty aggregates.
+//     Example: nil -> "_\r\n", []int(nil) -> "_\r\n", []int{} -> "*0\r\n"
 //
-//   - **Errors**: Encodes Go error types as RESP3 errors.
+//   - **Errors**: Encodes Go error types as RESP3 errors using their full Error() text, which
+//     includes the messages of any errors wrapped with fmt.Errorf("...: %w", err).
 //     Example: errors.New("error message") -> "-error message\r\n"
 //
+//     Messages holding control bytes such as CR or LF, which would otherwise inject frames into
+//     the stream, are encoded as binary-safe blob errors instead. Under RESP2, which lacks blob
+//     errors, each control byte is replaced by a space.
+//     Example: errors.New("bad\r\ninput") -> "!10\r\nbad\r\ninput\r\n"
+//     A BlobError is always encoded as a blob error, whatever its text.
+//     Example: BlobError("ERR long message") -> "!16\r\nERR long message\r\n"
+//     Slices of errors and maps with error values, such as []error or map[string]error for the
+//     outcome of a batch of operations, encode each error the same way and each nil error as null.
+//     Example: []error{errors.New("ERR a"), nil} -> "*2\r\n-ERR a\r\n_\r\n"
+//
+//   - **Bytes**: Encodes []byte, named byte slices and fixed-size byte arrays such as [16]byte
+//     as binary-safe RESP3 bulk strings, and [][]byte as an array of them.
+//     Example: []byte("hi") -> "$2\r\nhi\r\n", [][]byte{[]byte("hi")} -> "*1\r\n$2\r\nhi\r\n"
+//
 //   - **Slices**: Supports slices of any type (e.g., []string, []int, []float64, etc.) and encodes them as RESP3 arrays.
 //     Each element of the slice is recursively encoded using the same rules.
 //     Example: []int{1, 2, 3} -> "*3\r\n:1\r\n:2\r\n:3\r\n"
 //
+//     Slices and arrays of any other element type (e.g. []ScalarRecord, []time.Time, [3]int) are
+//     encoded the same way through reflection.
+//
+//   - **Pointers**: A nil pointer encodes as RESP3 null, any other pointer as the value it points to.
+//
 //   - **Maps**: Supports maps with either string keys or interface{} keys (e.g., map[string]interface{}, map[interface{}]interface{}).
 //     The key-value pairs are encoded as RESP3 maps. The keys and values are recursively encoded.
+//     The common shapes map[string][]string and map[string]int have dedicated cases; maps of any
+//     other key and value types (e.g. map[int64]string) are encoded the same way through reflection.
+//     Entries follow Go's randomized map order; use EncodeSorted for deterministic output.
 //     Example: map[string]interface{}{"a": 1, "b": 2} -> "%4\r\n+a\r\n:1\r\n+b\r\n:2\r\n"
 //
+//   - **OrderedMap**: Encodes an OrderedMap as a RESP3 map with its entries in slice order,
+//     which is kept even when keys would otherwise be sorted.
+//     Example: OrderedMap{{Key: "b", Value: 1}, {Key: "a", Value: 2}} -> "%4\r\n+b\r\n:1\r\n+a\r\n:2\r\n"
+//
 //   - **Structs**: Encodes Go structs by treating field names as map keys and field values as map values.
 //     Each field is recursively encoded.
 //     Example: struct{ Name string; Age int } -> "%4\r\n+Name\r\n$5\r\nAlice\r\n+Age\r\n:25\r\n"
 //
-//   - **time.Time**: Encodes time.Time values as Unix timestamps in milliseconds.
+//   - **time.Time**: Encodes time.Time values as Unix timestamps in milliseconds. An Encoder
+//     can use Unix seconds or RFC 3339 bulk strings instead through its TimeFormat option.
+//     Slices such as []time.Time encode each element the same way, as an array.
 //     Example: time.Now() -> ":1620832335000\r\n"
 //
+//   - **time.Duration**: Encodes time.Duration values as integer nanoseconds. An Encoder can
+//     emit them as bulk strings such as "1.5s" through its DurationAsString option.
+//     Example: 1500 * time.Millisecond -> ":1500000000\r\n"
+//
+//   - **driver.Valuer**: Values implementing database/sql/driver.Valuer, such as sql.NullString
+//     or sql.NullInt64, are encoded through the value they report; an invalid one, or a nil
+//     pointer to one, encodes as null.
+//     Example: sql.NullInt64{Int64: 7, Valid: true} -> ":7\r\n", sql.NullString{} -> "_\r\n"
+//
+//   - **Attributed**: Encodes the Attributes of an Attributed value as a RESP3 attribute map
+//     ("|") followed by its Value. Without attributes, or under RESP2, only the Value is written.
+//     Example: Attributed{Attributes: map[string]interface{}{"ttl": 60}, Value: "v"} -> "|2\r\n+ttl\r\n:60\r\n+v\r\n"
+//
+//   - **PushMessage**: Encodes a PushMessage as a RESP3 push (">") of its elements, or as an
+//     array under RESP2, which has no pushes.
+//     Example: PushMessage{"message", "news", "hi"} -> ">3\r\n+message\r\n+news\r\n+hi\r\n"
+//
+//   - **Value**: Encodes a Value in the wire form recorded in its Type, keeping the distinctions
+//     that native Go types lose, such as simple versus bulk strings or sets versus arrays.
+//     Under RESP2 the types that RESP2 lacks are downgraded as for native Go types.
+//     Example: Value{Type: TypeBulkString, Str: "OK"} -> "$2\r\nOK\r\n"
+//
 //   - **Custom Types**: Custom types (like ScalarRecord or RecordResponse) are handled by converting them to maps and encoding them recursively.
+//     Their EncodeArray methods encode them positionally as arrays of field values instead.
 //
 // Parameters:
 //   - value: The Go value to be encoded. This value can be of any supported type, including
@@ -58,7 +151,8 @@ import (
 //   - string: The RESP3-encoded string representation of the input value.
 //
 //   - error: An error is returned if the value type is not supported for encoding, or if
-//     any issue arises during the encoding process.
+//     any issue arises during the encoding process. Unsupported types are reported with an
+//     error wrapping ErrUnsupportedEncodeType, which can be detected with errors.Is.
 //
 // Example Usage:
 //
@@ -71,171 +165,798 @@ import (
 // will have their elements or fields encoded individually according to their respective types.
 // This ensures that nested data structures can be efficiently serialized into RESP3 format.
 func Encode(value interface{}) (string, error) {
+	var sb strings.Builder
+	if err := (&Encoder{}).encode(&sb, value); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// EncodeSorted behaves like Encode but emits map entries in a deterministic order,
+// which makes the output suitable for golden files and content-addressed caching.
+// Keys are ordered strings first (lexicographically), then integers (numerically),
+// then floats (numerically), then booleans (false before true), and finally keys
+// of any other type by their Go syntax representation.
+func EncodeSorted(value interface{}) (string, error) {
+	var sb strings.Builder
+	if err := (&Encoder{SortKeys: true}).encode(&sb, value); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// EncodeCanonical encodes value into a canonical form: map entries are sorted as in
+// EncodeSorted at every nesting level, and string keys are always bulk strings.
+// Equal values therefore always produce identical bytes, which makes the output
+// suitable for hashing or signing RESP payloads.
+func EncodeCanonical(value interface{}) (string, error) {
+	var sb strings.Builder
+	if err := (&Encoder{SortKeys: true, BulkStringKeys: true}).encode(&sb, value); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// EncodeCommand encodes a command and its arguments as an array of bulk strings,
+// the form in which clients send commands to a server. Arguments may be given as
+// strings or, to build requests straight from buffers without converting them,
+// as byte slices.
+//
+// Parameters:
+//   - args ...T: The command name followed by its arguments, all strings or all byte slices.
+//
+// Returns:
+//   - string: The encoded command.
+//
+// Example usage:
+//
+//	cmd := EncodeCommand("SET", "key", "value")
+//	// cmd == "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n"
+//
+//	cmd = EncodeCommand([]byte("GET"), keyBuf)
+func EncodeCommand[T string | []byte](args ...T) string {
+	size := 16
+	for _, arg := range args {
+		size += len(arg) + 16
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+	writeHeader(&sb, '*', len(args))
+
+	switch args := any(args).(type) {
+	case []string:
+		for _, arg := range args {
+			writeBulkString(&sb, arg)
+		}
+	case [][]byte:
+		for _, arg := range args {
+			writeBulkBytes(&sb, arg)
+		}
+	}
+	return sb.String()
+}
+
+// EncodeMulti encodes several commands back to back, each as EncodeCommand would,
+// for pipelining them in a single write. The output buffer is sized once for all
+// of the commands.
+//
+// Parameters:
+//   - cmds [][]string: The commands, each a command name followed by its arguments.
+//
+// Returns:
+//   - string: The encoded commands, concatenated in order.
+//   - error: An error if a command is empty, as every command needs a name.
+//
+// Example usage:
+//
+//	pipeline, err := EncodeMulti([][]string{{"SET", "k", "v"}, {"GET", "k"}})
+//	// pipeline == "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n*2\r\n$3\r\nGET\r\n$1\r\nk\r\n"
+func EncodeMulti(cmds [][]string) (string, error) {
+	size := 0
+	for i, cmd := range cmds {
+		if len(cmd) == 0 {
+			return "", fmt.Errorf("command %d is empty", i)
+		}
+		size += 16
+		for _, arg := range cmd {
+			size += len(arg) + 16
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+	for _, cmd := range cmds {
+		writeHeader(&sb, '*', len(cmd))
+		for _, arg := range cmd {
+			writeBulkString(&sb, arg)
+		}
+	}
+	return sb.String(), nil
+}
+
+// Encoder writes RESP3 encoded values to an io.Writer. Its exported fields are
+// options that may be set before encoding; the zero value of each option keeps
+// the behaviour of the package-level Encode function.
+type Encoder struct {
+	w io.Writer
+
+	// SkipUnsupported omits array elements and map entries whose key or value
+	// has a type that cannot be encoded (e.g. chan or func), adjusting the
+	// aggregate header count, instead of failing the whole encode.
+	SkipUnsupported bool
+
+	// MaxOutputSize, when positive, caps the size in bytes of a single encoded
+	// value. Encoding is aborted with ErrLimitExceeded as soon as the output
+	// grows past the cap, which bounds the work spent on user-controlled input.
+	MaxOutputSize int
+
+	// SortKeys emits map entries in a deterministic order instead of Go's
+	// randomized map iteration order, so equal maps always encode to identical
+	// bytes. String keys sort lexicographically and integer keys of any width
+	// numerically; keys of mixed types follow the order described on EncodeSorted,
+	// which places strings before integers. Nested maps are sorted as well.
+	SortKeys bool
+
+	// ZeroTimeAsNull encodes the zero time.Time as RESP3 null rather than as the
+	// meaningless timestamp of January 1, year 1.
+	ZeroTimeAsNull bool
+
+	// TimeFormat selects how time.Time values are encoded. The zero value,
+	// UnixMillis, keeps the default integer millisecond timestamp.
+	TimeFormat TimeFormat
+
+	// DurationAsString encodes time.Duration values as bulk strings in the
+	// form produced by Duration.String, such as "1.5s", instead of as integer
+	// nanoseconds.
+	DurationAsString bool
+
+	// Protocol selects the protocol version to encode for. The zero value is
+	// RESP3; RESP2 downgrades the types that RESP2 servers reject as described
+	// on the Protocol type.
+	Protocol Protocol
+
+	// BulkStringKeys encodes string map keys and struct field names as bulk
+	// strings instead of simple strings, so every key has a single wire form
+	// regardless of its content.
+	BulkStringKeys bool
+
+	// Stringers encodes values implementing fmt.Stringer that match none of the
+	// explicitly supported types as bulk strings of their String method, rather
+	// than as maps of their fields or as unsupported. Nil pointers still encode
+	// as null.
+	Stringers bool
+
+	// SimpleStringMaxLen is the longest string, in bytes, encoded as a simple
+	// string; longer strings are encoded as bulk strings. Zero keeps the default
+	// of 16 and a negative value encodes every string as a bulk string, for
+	// servers that only accept bulk strings as arguments. Strings containing CR
+	// or LF are always encoded as bulk strings, whatever their length.
+	SimpleStringMaxLen int
+}
+
+// Protocol is the RESP protocol version an Encoder writes.
+//
+// Under RESP2 the types that only exist in RESP3 are downgraded as follows:
+//
+//   - Maps and structs flatten to arrays of alternating keys and values:
+//     map[string]interface{}{"a": 1} -> "*2\r\n+a\r\n:1\r\n"
+//   - Booleans become the integers 1 and 0: true -> ":1\r\n", false -> ":0\r\n"
+//   - Floats become bulk strings of the same text: 3.14 -> "$8\r\n3.140000\r\n"
+//   - Verbatim strings become bulk strings of their content, dropping the format.
+//   - Errors whose message holds control bytes, written as blob errors under RESP3,
+//     stay simple errors with each control byte replaced by a space.
+//   - Nil, nil pointers and ZeroTimeAsNull times become the null bulk string "$-1\r\n",
+//     and nil slices and maps the null array "*-1\r\n".
+type Protocol int
+
+const (
+	// RESP3 encodes every value in its native RESP3 form.
+	RESP3 Protocol = iota
+	// RESP2 restricts the output to types understood by RESP2 servers.
+	RESP2
+)
+
+// TimeFormat is the wire representation used by an Encoder for time.Time values.
+type TimeFormat int
+
+const (
+	// UnixMillis encodes a time as an integer number of milliseconds since the Unix epoch.
+	UnixMillis TimeFormat = iota
+	// UnixSeconds encodes a time as an integer number of seconds since the Unix epoch.
+	UnixSeconds
+	// RFC3339 encodes a time as a bulk string in RFC 3339 format, with sub-second
+	// precision when present.
+	RFC3339
+)
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the RESP3 encoding of value to the underlying writer. See the
+// package-level Encode function for the supported types.
+func (e *Encoder) Encode(value interface{}) error {
+	var sb strings.Builder
+	if err := e.encode(&sb, value); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+// EncodeStream writes the values received from ch as a RESP3 streamed array
+// ("*?\r\n", the elements, then ".\r\n"), draining ch until it is closed. Each
+// element is written as soon as it is received, so a lazily produced collection
+// is never held in memory as a whole. Elements are encoded like the elements of
+// a slice passed to Encode, except that SkipUnsupported does not apply.
+//
+// Under RESP2, which lacks streamed aggregates, the values are collected until ch
+// is closed and then written as a counted array.
+//
+// An element that fails to encode stops the stream part way through the array,
+// which leaves the peer unable to read further replies, so the connection should
+// then be closed. ch is not drained after such a failure.
+//
+// Example usage:
+//
+//	ch := make(chan interface{})
+//	go func() {
+//	    defer close(ch)
+//	    ch <- "a"
+//	    ch <- 1
+//	}()
+//	err := enc.EncodeStream(ch) // "*?\r\n+a\r\n:1\r\n.\r\n"
+func (e *Encoder) EncodeStream(ch <-chan interface{}) error {
+	if e.Protocol == RESP2 {
+		var elements []interface{}
+		for elem := range ch {
+			elements = append(elements, elem)
+		}
+
+		// Encoded one by one rather than as a slice, whose elements SkipUnsupported
+		// would drop
+		var sb strings.Builder
+		writeHeader(&sb, '*', len(elements))
+		for i, elem := range elements {
+			if err := e.encode(&sb, elem); err != nil {
+				return fmt.Errorf("encode index %d: %w", i, err)
+			}
+		}
+		_, err := io.WriteString(e.w, sb.String())
+		return err
+	}
+
+	if _, err := io.WriteString(e.w, "*?\r\n"); err != nil {
+		return err
+	}
+
+	i := 0
+	for elem := range ch {
+		if err := e.Encode(elem); err != nil {
+			return fmt.Errorf("encode index %d: %w", i, err)
+		}
+		i++
+	}
+
+	_, err := io.WriteString(e.w, ".\r\n")
+	return err
+}
+
+// WriteMulti writes several commands for pipelining, encoded as by EncodeMulti,
+// with a single Write call. Nothing is written if a command is empty.
+func (e *Encoder) WriteMulti(cmds [][]string) error {
+	pipeline, err := EncodeMulti(cmds)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, pipeline)
+	return err
+}
+
+// skippable reports whether err may be dropped under the SkipUnsupported option.
+func (e *Encoder) skippable(err error) bool {
+	return e.SkipUnsupported && errors.Is(err, ErrUnsupportedEncodeType)
+}
+
+// encode appends the RESP3 encoding of value to sb, enforcing MaxOutputSize.
+func (e *Encoder) encode(sb *strings.Builder, value interface{}) error {
+	if err := e.encodeValue(sb, value); err != nil {
+		return err
+	}
+
+	if e.MaxOutputSize > 0 && sb.Len() > e.MaxOutputSize {
+		return fmt.Errorf("encoded output exceeds %d bytes: %w", e.MaxOutputSize, ErrLimitExceeded)
+	}
+	return nil
+}
+
+func (e *Encoder) encodeValue(sb *strings.Builder, value interface{}) error {
+	// Nil slices and maps encode as null so "absent" stays distinct from "empty"
+	if rv := reflect.ValueOf(value); (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil() {
+		if e.Protocol == RESP2 {
+			sb.WriteString("*-1\r\n") // Null array
+			return nil
+		}
+		sb.WriteString("_\r\n")
+		return nil
+	}
+
 	switch v := value.(type) {
 
 	// Strings
 	case string:
-		// If the string is short enough (less than 16 chars), use Simple String
-		if len(v) <= (1 << 4) {
-			return "+" + v + "\r\n", nil // Simple String
+		e.writeString(sb, v)
+		return nil
+
+	// Simple strings keep their wire form, so they cannot hold CR or LF
+	case SimpleString:
+		if strings.ContainsAny(string(v), "\r\n") {
+			return fmt.Errorf("simple string %q contains CR or LF", string(v))
 		}
-		// Otherwise, treat it as a Bulk String
-		return "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n", nil
+		sb.WriteString("+" + string(v) + "\r\n")
+		return nil
+
+	// Raw JSON is emitted verbatim as a binary-safe Bulk String
+	case json.RawMessage:
+		writeBulkString(sb, string(v))
+		return nil
+
+	// JSON numbers keep integers as integers and encode anything else as a double
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return e.encode(sb, i)
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return e.encode(sb, u)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid json.Number %q", string(v))
+		}
+		return e.encode(sb, f)
+
+	// Verbatim strings, the format must be a three byte hint such as "txt"
+	case VerbatimString:
+		if len(v.Format) != 3 {
+			return fmt.Errorf("verbatim string format must be exactly 3 bytes, got %q", v.Format)
+		}
+		if e.Protocol == RESP2 {
+			writeBulkString(sb, v.Content)
+			return nil
+		}
+		sb.WriteString("=" + strconv.Itoa(len(v.Format)+1+len(v.Content)) + "\r\n" + v.Format + ":" + v.Content + "\r\n")
+		return nil
 
 	// Integers and their variations
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		return ":" + fmt.Sprintf("%d", v) + "\r\n", nil
+		fmt.Fprintf(sb, ":%d\r\n", v)
+		return nil
 
 	// Floats
-	case float32, float64:
-		return "," + fmt.Sprintf("%f", v) + "\r\n", nil
+	case float32:
+		e.writeDouble(sb, formatDouble(float64(v), 32))
+		return nil
+
+	case float64:
+		e.writeDouble(sb, formatDouble(v, 64))
+		return nil
+
+	// Complex numbers, which RESP3 lacks a type for, are [real, imag] pairs of doubles
+	case complex64:
+		e.encodeComplex(sb, complex128(v), 32)
+		return nil
+
+	case complex128:
+		e.encodeComplex(sb, v, 64)
+		return nil
+
+	case *big.Float:
+		if v == nil {
+			e.writeNull(sb)
+			return nil
+		}
+		e.writeDouble(sb, formatBigFloat(v))
+		return nil
 
 	// Boolean
 	case bool:
+		if e.Protocol == RESP2 {
+			if v {
+				sb.WriteString(":1\r\n")
+				return nil
+			}
+			sb.WriteString(":0\r\n")
+			return nil
+		}
 		if v {
-			return "#t\r\n", nil
+			sb.WriteString("#t\r\n")
+			return nil
 		}
-		return "#f\r\n", nil
+		sb.WriteString("#f\r\n")
+		return nil
 
 	// Nil
 	case nil:
-		return "_\r\n", nil
+		e.writeNull(sb)
+		return nil
 
 	// Error
+	case BlobError:
+		e.writeBlobError(sb, string(v))
+		return nil
+
 	case error:
-		return "-" + v.Error() + "\r\n", nil
+		e.writeError(sb, v.Error())
+		return nil
 
 		// Arrays of interface{}
 	case []interface{}:
-		resp := "*" + strconv.Itoa(len(v)) + "\r\n"
-		for _, elem := range v {
-			// Handle strings separately to use Simple Strings for short text
-			if str, ok := elem.(string); ok && len(str) <= 12 {
-				resp += "+" + str + "\r\n" // Use Simple String for short strings
-			} else {
-				encodedElem, err := Encode(elem)
-				if err != nil {
-					return "", err
-				}
-				resp += encodedElem
-			}
-		}
-		return resp, nil
+		return e.encodeArray(sb, len(v), func(sb *strings.Builder, i int) error {
+			return e.encode(sb, v[i])
+		})
+
+		// Arrays of errors, each encoded as an error frame or as null if nil
+	case []error:
+		return e.encodeArray(sb, len(v), func(sb *strings.Builder, i int) error {
+			return e.encode(sb, v[i])
+		})
 
 		// Arrays of strings
 	case []string:
-		resp := "*" + strconv.Itoa(len(v)) + "\r\n"
+		size := 0
 		for _, elem := range v {
-			encodedElem := "+" + elem + "\r\n" // Change to Simple String
-			resp += encodedElem
+			size += len(elem) + 3
 		}
-		return resp, nil
+		sb.Grow(size + 16)
 
-	// Arrays of integers (all int types)
-	case []int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16, []uint32, []uint64:
-		val := reflect.ValueOf(v)
-		resp := "*" + strconv.Itoa(val.Len()) + "\r\n"
-		for i := 0; i < val.Len(); i++ {
-			encodedElem, err := Encode(val.Index(i).Interface())
-			if err != nil {
-				return "", err
-			}
-			resp += encodedElem
+		writeHeader(sb, '*', len(v))
+		for _, elem := range v {
+			e.writeString(sb, elem)
+		}
+		return nil
+
+	// Byte slices are binary data, encoded as a Bulk String
+	case []byte:
+		writeBulkBytes(sb, v)
+		return nil
+
+	// Slices of byte slices, such as command arguments, are arrays of Bulk Strings
+	case [][]byte:
+		writeHeader(sb, '*', len(v))
+		for _, elem := range v {
+			writeBulkBytes(sb, elem)
 		}
-		return resp, nil
+		return nil
+
+	// Arrays of integers (all int types)
+	case []int, []int8, []int16, []int32, []int64, []uint, []uint16, []uint32, []uint64:
+		return e.encodeSlice(sb, reflect.ValueOf(v))
 
 	// Arrays of bools
 	case []bool:
-		resp := "*" + strconv.Itoa(len(v)) + "\r\n"
+		sb.Grow(len(v)*4 + 16)
+		writeHeader(sb, '*', len(v))
 		for _, elem := range v {
-			encodedElem, err := Encode(elem)
-			if err != nil {
-				return "", err
+			if err := e.encode(sb, elem); err != nil {
+				return err
 			}
-			resp += encodedElem
 		}
-		return resp, nil
+		return nil
 
 	// Arrays of float32 and float64
 	case []float32, []float64:
-		val := reflect.ValueOf(v)
-		resp := "*" + strconv.Itoa(val.Len()) + "\r\n"
-		for i := 0; i < val.Len(); i++ {
-			encodedElem, err := Encode(val.Index(i).Interface())
-			if err != nil {
-				return "", err
-			}
-			resp += encodedElem
-		}
-		return resp, nil
+		return e.encodeSlice(sb, reflect.ValueOf(v))
 
 	// Map with string keys and interface values
 	case map[string]interface{}:
-		resp := "%" + strconv.Itoa(len(v)*2) + "\r\n"
-		for kx, vx := range v {
-			resp += "+" + kx + "\r\n"
-			valueStr, err := Encode(vx)
-			if err != nil {
-				return "", err
-			}
-			resp += valueStr
-		}
-		return resp, nil
+		return e.encodeMap(sb, len(v), mapEntries(v))
+
+	// Header-style maps of string lists
+	case map[string][]string:
+		return e.encodeMap(sb, len(v), mapEntries(v))
+
+	// Per-key error maps, such as the outcome of a batch of operations; nil
+	// errors encode as null
+	case map[string]error:
+		return e.encodeMap(sb, len(v), mapEntries(v))
+
+	// Counter maps
+	case map[string]int:
+		return e.encodeMap(sb, len(v), mapEntries(v))
 
 		// Map with interface{} keys and values (map[interface{}]interface{})
 	case map[interface{}]interface{}:
-		resp := "%" + strconv.Itoa(len(v)*2) + "\r\n"
-		for kx, vx := range v {
-			var keyStr string
-			var err error
-
-			// Check the type of the key and encode accordingly
-			switch key := kx.(type) {
-			case string:
-				keyStr = "+" + key + "\r\n" // Simple string
-
-			default:
-				keyStr, err = Encode(key) // Other types
-			}
+		return e.encodeMap(sb, len(v), mapEntries(v))
 
-			if err != nil {
-				return "", err
-			}
+	// Tagged values are written in the wire form of their type
+	case Value:
+		return e.encodeTagged(sb, v)
+
+	// Pushes keep their type under RESP3 and become arrays under RESP2
+	case PushMessage:
+		return e.encodeAggregate(sb, e.pushMarker(), len(v), func(sb *strings.Builder, i int) error {
+			return e.encode(sb, v[i])
+		})
 
-			valueStr, err := Encode(vx)
-			if err != nil {
-				return "", err
+	// Attributed values are preceded by their attribute map
+	case Attributed:
+		return e.encodeAttributed(sb, v)
+
+	// OrderedMap keeps its entries in their given order, even under SortKeys
+	case OrderedMap:
+		return e.encodeEntries(sb, len(v), func(entry func(key, value interface{}) error) error {
+			for _, kv := range v {
+				if err := entry(kv.Key, kv.Value); err != nil {
+					return err
+				}
 			}
+			return nil
+		})
 
-			resp += keyStr + valueStr
+	// time.Time encoded as Unix timestamp in milliseconds unless TimeFormat says otherwise
+	case time.Time:
+		if e.ZeroTimeAsNull && v.IsZero() {
+			e.writeNull(sb)
+			return nil
 		}
-		return resp, nil
+		switch e.TimeFormat {
+		case UnixSeconds:
+			sb.WriteString(":" + strconv.FormatInt(v.Unix(), 10) + "\r\n")
+		case RFC3339:
+			writeBulkString(sb, v.Format(time.RFC3339Nano))
+		default:
+			sb.WriteString(":" + strconv.FormatInt(v.UnixMilli(), 10) + "\r\n")
+		}
+		return nil
 
-	// time.Time encoded as Unix timestamp in milliseconds
-	case time.Time:
-		return ":" + strconv.FormatInt(v.UnixMilli(), 10) + "\r\n", nil
+	// time.Duration encoded as integer nanoseconds unless DurationAsString is set
+	case time.Duration:
+		if e.DurationAsString {
+			writeBulkString(sb, v.String())
+			return nil
+		}
+		sb.WriteString(":" + strconv.FormatInt(int64(v), 10) + "\r\n")
+		return nil
+
+	// database/sql values such as sql.NullString and sql.NullInt64 are encoded
+	// through their driver value, so an invalid (NULL) value encodes as RESP3 null
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			e.writeNull(sb) // e.g. a nil *sql.NullString, whose Value method would panic
+			return nil
+		}
+		driverValue, err := v.Value()
+		if err != nil {
+			return err
+		}
+		return e.encode(sb, driverValue)
 
 	// Handle structs
 	case struct{}:
-		return encodeStruct(v)
+		return e.encodeStruct(sb, v)
 
 	default:
-		// Handle structs through reflection if no direct case matches
+		// Handle structs, pointers and other slices and maps through reflection if no direct case matches
 		rv := reflect.ValueOf(value)
-		if rv.Kind() == reflect.Struct {
-			return encodeStruct(rv.Interface())
+		if stringer, ok := value.(fmt.Stringer); ok && e.Stringers && !(rv.Kind() == reflect.Pointer && rv.IsNil()) {
+			writeBulkString(sb, stringer.String())
+			return nil
+		}
+
+		switch rv.Kind() {
+		case reflect.Struct:
+			return e.encodeStruct(sb, rv.Interface())
+
+		case reflect.Pointer:
+			if rv.IsNil() {
+				e.writeNull(sb)
+				return nil
+			}
+			return e.encode(sb, rv.Elem().Interface())
+
+		case reflect.Slice, reflect.Array:
+			// Fixed-size byte arrays (e.g. [16]byte UUIDs) and named byte slices are binary data too
+			if rv.Type().Elem().Kind() == reflect.Uint8 {
+				raw := make([]byte, rv.Len())
+				reflect.Copy(reflect.ValueOf(raw), rv)
+				writeBulkString(sb, string(raw))
+				return nil
+			}
+			return e.encodeSlice(sb, rv)
+
+		case reflect.Map:
+			return e.encodeMap(sb, rv.Len(), func(entry func(key, value interface{}) error) error {
+				iter := rv.MapRange()
+				for iter.Next() {
+					if err := entry(iter.Key().Interface(), iter.Value().Interface()); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		case reflect.Complex64, reflect.Complex128:
+			e.encodeComplex(sb, rv.Complex(), rv.Type().Bits()/2)
+			return nil
+
+		// Kinds that have no RESP3 representation at all
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			return fmt.Errorf("unsupported type %v, convert the value to a supported type before encoding it: %w", rv.Type(), ErrUnsupportedEncodeType)
+		}
+
+		return fmt.Errorf("unsupported type %v: %w", reflect.TypeOf(value), ErrUnsupportedEncodeType)
+	}
+}
+
+// encodeSlice encodes the elements of a slice or array of any element type as a
+// RESP3 array, recursing into each element.
+func (e *Encoder) encodeSlice(sb *strings.Builder, val reflect.Value) error {
+	sb.Grow(val.Len()*8 + 16)
+	return e.encodeArray(sb, val.Len(), func(sb *strings.Builder, i int) error {
+		return e.encode(sb, val.Index(i).Interface())
+	})
+}
+
+// encodeArray encodes size elements, each written by calling encodeItem with its
+// index, as a RESP3 array. Element errors are annotated with the failing index.
+// Under SkipUnsupported the elements are buffered so that those which fail to
+// encode can be dropped and the header count adjusted.
+func (e *Encoder) encodeArray(sb *strings.Builder, size int, encodeItem func(sb *strings.Builder, i int) error) error {
+	return e.encodeAggregate(sb, '*', size, encodeItem)
+}
+
+// encodeAggregate is encodeArray for an aggregate of the type marked by marker,
+// such as a push.
+func (e *Encoder) encodeAggregate(sb *strings.Builder, marker byte, size int, encodeItem func(sb *strings.Builder, i int) error) error {
+	if !e.SkipUnsupported {
+		writeHeader(sb, marker, size)
+		for i := 0; i < size; i++ {
+			if err := encodeItem(sb, i); err != nil {
+				return fmt.Errorf("encode index %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	var body strings.Builder
+	count := 0
+	for i := 0; i < size; i++ {
+		var item strings.Builder
+		if err := encodeItem(&item, i); err != nil {
+			if e.skippable(err) {
+				continue
+			}
+			return fmt.Errorf("encode index %d: %w", i, err)
+		}
+
+		body.WriteString(item.String())
+		count++
+	}
+
+	writeHeader(sb, marker, count)
+	sb.WriteString(body.String())
+	return nil
+}
+
+// encodeMap encodes size key/value pairs, produced by calling entries with a
+// callback for each pair, as a RESP3 map. Keys are written as described on
+// encodeMapKey, and pairs are reordered first when SortKeys is set. Under
+// SkipUnsupported the entries are buffered so that pairs which fail to encode
+// can be dropped and the header count adjusted.
+func (e *Encoder) encodeMap(sb *strings.Builder, size int, entries func(entry func(key, value interface{}) error) error) error {
+	if e.SortKeys {
+		entries = sortedEntries(size, entries)
+	}
+	return e.encodeEntries(sb, size, entries)
+}
+
+// mapEntries returns the entries callback expected by encodeMap for a map of
+// any key and value types, so typed maps are encoded without reflection.
+func mapEntries[K comparable, V any](m map[K]V) func(entry func(key, value interface{}) error) error {
+	return func(entry func(key, value interface{}) error) error {
+		for k, v := range m {
+			if err := entry(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// encodeEntries encodes the pairs produced by entries as a RESP3 map in the
+// order they are produced.
+func (e *Encoder) encodeEntries(sb *strings.Builder, size int, entries func(entry func(key, value interface{}) error) error) error {
+	if !e.SkipUnsupported {
+		writeHeader(sb, e.mapMarker(), size*2)
+		return entries(func(key, value interface{}) error {
+			if err := e.encodeMapKey(sb, key); err != nil {
+				return err
+			}
+			return e.encode(sb, value)
+		})
+	}
+
+	var body strings.Builder
+	count := 0
+	err := entries(func(key, value interface{}) error {
+		var entry strings.Builder
+		err := e.encodeMapKey(&entry, key)
+		if err == nil {
+			err = e.encode(&entry, value)
+		}
+
+		if err != nil {
+			if e.skippable(err) {
+				return nil
+			}
+			return err
 		}
 
-		return "", fmt.Errorf("unsupported type: %v", reflect.TypeOf(value))
+		body.WriteString(entry.String())
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writeHeader(sb, e.mapMarker(), count*2)
+	sb.WriteString(body.String())
+	return nil
+}
+
+// encodeAttributed writes the attributes of v as a "|" map followed by its value.
+// RESP2 has no attributes, so only the value is written there, as it is when
+// there are no attributes to send.
+func (e *Encoder) encodeAttributed(sb *strings.Builder, v Attributed) error {
+	if len(v.Attributes) == 0 || e.Protocol == RESP2 {
+		return e.encode(sb, v.Value)
+	}
+
+	// Attributes share the map encoding and differ only in their type marker
+	var attrs strings.Builder
+	if err := e.encode(&attrs, v.Attributes); err != nil {
+		return fmt.Errorf("encode attributes: %w", err)
+	}
+	encoded := attrs.String()
+	if !strings.HasPrefix(encoded, "%") {
+		return fmt.Errorf("attributes encoded as %q rather than a map: %w", encoded, ErrProtocol)
+	}
+
+	sb.WriteString("|" + encoded[1:])
+	return e.encode(sb, v.Value)
+}
+
+// encodeMapKey encodes a map key, using a simple string for string keys unless
+// BulkStringKeys is set or the key contains CR or LF, which a simple string
+// cannot carry.
+func (e *Encoder) encodeMapKey(sb *strings.Builder, key interface{}) error {
+	if str, ok := key.(string); ok {
+		if e.BulkStringKeys || strings.ContainsAny(str, "\r\n") {
+			writeBulkString(sb, str)
+			return nil
+		}
+		sb.WriteString("+" + str + "\r\n") // Simple string
+		return nil
 	}
+	return e.encode(sb, key) // Other types
 }
 
-func encodeStruct(s interface{}) (string, error) {
+func (e *Encoder) encodeStruct(sb *strings.Builder, s interface{}) error {
 	val := reflect.ValueOf(s)
 	typ := val.Type()
 
-	// Create the response map based on the number of exported fields
-	resp := "%" + strconv.Itoa(val.NumField()*2) + "\r\n"
+	// Create the response map based on the number of exported fields.
+	// Zero-valued fields are still emitted, so the header always matches.
+	exported := 0
+	for i := 0; i < val.NumField(); i++ {
+		if typ.Field(i).IsExported() {
+			exported++
+		}
+	}
+	writeHeader(sb, e.mapMarker(), exported*2)
 
 	for i := 0; i < val.NumField(); i++ {
 		field := typ.Field(i)
@@ -246,17 +967,175 @@ func encodeStruct(s interface{}) (string, error) {
 		}
 
 		fieldName := field.Name
-		fieldValue := val.Field(i).Interface()
+		fieldValue := val.Field(i).Interface() // a nil interface{} field yields nil and encodes as "_"
 
-		resp += "+" + fieldName + "\r\n"
+		if err := e.encodeMapKey(sb, fieldName); err != nil {
+			return err
+		}
 
-		encodedValue, err := Encode(fieldValue)
-		if err != nil {
-			return "", err
+		if err := e.encode(sb, fieldValue); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
 
-		resp += encodedValue
+// writeDouble writes the formatted text of a double, which RESP2 lacks a
+// dedicated type for and expresses as a bulk string.
+func (e *Encoder) writeDouble(sb *strings.Builder, text string) {
+	if e.Protocol == RESP2 {
+		writeBulkString(sb, text)
+		return
 	}
+	sb.WriteString("," + text + "\r\n")
+}
+
+// formatDouble formats f, a float of the given bit size, as RESP3 double text.
+func formatDouble(f float64, bitSize int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	text := strconv.FormatFloat(f, 'f', 6, bitSize)
+	if parsed, _ := strconv.ParseFloat(text, bitSize); parsed != f {
+		text = strconv.FormatFloat(f, 'g', -1, bitSize) // Six decimals would lose precision
+	}
+	return text
+}
+
+// encodeComplex writes c as a two-element array of its real and imaginary parts,
+// each a double of the given bit size.
+func (e *Encoder) encodeComplex(sb *strings.Builder, c complex128, bitSize int) {
+	writeHeader(sb, '*', 2)
+	e.writeDouble(sb, formatDouble(real(c), bitSize))
+	e.writeDouble(sb, formatDouble(imag(c), bitSize))
+}
+
+// formatBigFloat formats f as RESP3 double text, keeping every digit of its precision.
+func formatBigFloat(f *big.Float) string {
+	if f.IsInf() {
+		if f.Sign() > 0 {
+			return "inf"
+		}
+		return "-inf"
+	}
+	return f.Text('g', -1)
+}
+
+// defaultSimpleStringMaxLen is the longest string, in bytes, that is encoded as a
+// simple string when SimpleStringMaxLen is not set.
+const defaultSimpleStringMaxLen = 16
+
+// writeString writes s as a simple string if it is short enough and free of CR
+// and LF, which a simple string cannot carry, and as a bulk string otherwise.
+func (e *Encoder) writeString(sb *strings.Builder, s string) {
+	if len(s) <= e.simpleStringMaxLen() && !strings.ContainsAny(s, "\r\n") {
+		sb.WriteString("+" + s + "\r\n")
+		return
+	}
+	writeBulkString(sb, s)
+}
+
+// simpleStringMaxLen returns the effective SimpleStringMaxLen, -1 meaning that
+// every string is encoded as a bulk string.
+func (e *Encoder) simpleStringMaxLen() int {
+	switch {
+	case e.SimpleStringMaxLen < 0:
+		return -1
+	case e.SimpleStringMaxLen == 0:
+		return defaultSimpleStringMaxLen
+	}
+	return e.SimpleStringMaxLen
+}
+
+// writeNull writes a null, which RESP2 lacks a dedicated type for and expresses
+// as a null bulk string.
+func (e *Encoder) writeNull(sb *strings.Builder) {
+	if e.Protocol == RESP2 {
+		sb.WriteString("$-1\r\n")
+		return
+	}
+	sb.WriteString("_\r\n")
+}
+
+// writeError writes an error with the given text. Text holding control bytes
+// such as CR or LF, which would let a message inject frames of its own into the
+// stream, is written as a binary-safe blob error instead of a simple error.
+// RESP2 lacks blob errors, so there each control byte is replaced by a space.
+func (e *Encoder) writeError(sb *strings.Builder, text string) {
+	if strings.IndexFunc(text, isControl) < 0 {
+		sb.WriteString("-" + text + "\r\n")
+		return
+	}
+
+	e.writeBlobError(sb, text)
+}
+
+// writeBlobError writes a blob error with the given text, or under RESP2 a simple
+// error with each control byte replaced by a space.
+func (e *Encoder) writeBlobError(sb *strings.Builder, text string) {
+	if e.Protocol == RESP2 {
+		sb.WriteString("-" + strings.Map(func(r rune) rune {
+			if isControl(r) {
+				return ' '
+			}
+			return r
+		}, text) + "\r\n")
+		return
+	}
+	sb.WriteString("!" + strconv.Itoa(len(text)) + "\r\n" + text + "\r\n")
+}
+
+// isControl reports whether r is an ASCII control character.
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// mapMarker returns the header marker for maps, which RESP2 flattens to arrays
+// of alternating keys and values.
+func (e *Encoder) mapMarker() byte {
+	if e.Protocol == RESP2 {
+		return '*'
+	}
+	return '%'
+}
+
+// pushMarker returns the header marker for pushes, which RESP2 lacks and which
+// are therefore written there as plain arrays.
+func (e *Encoder) pushMarker() byte {
+	if e.Protocol == RESP2 {
+		return '*'
+	}
+	return '>'
+}
+
+// writeBulkString writes s as a RESP3 bulk string such as "$5\r\nhello\r\n".
+func writeBulkString(sb *strings.Builder, s string) {
+	sb.WriteByte('$')
+	sb.WriteString(strconv.Itoa(len(s)))
+	sb.WriteString("\r\n")
+	sb.WriteString(s)
+	sb.WriteString("\r\n")
+}
+
+// writeBulkBytes writes b as a RESP3 bulk string without converting it to a string first.
+func writeBulkBytes(sb *strings.Builder, b []byte) {
+	sb.WriteByte('$')
+	sb.WriteString(strconv.Itoa(len(b)))
+	sb.WriteString("\r\n")
+	sb.Write(b)
+	sb.WriteString("\r\n")
+}
 
-	return resp, nil
+// writeHeader writes an aggregate header such as "*3\r\n" or "%4\r\n".
+func writeHeader(sb *strings.Builder, marker byte, count int) {
+	sb.WriteByte(marker)
+	sb.WriteString(strconv.Itoa(count))
+	sb.WriteString("\r\n")
 }
@@ -1,9 +1,18 @@
 package resp3
 
 import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 func TestEncode(t *testing.T) {
@@ -23,6 +32,41 @@ func TestEncode(t *testing.T) {
 			input:    "This is a long string of length > 16",
 			expected: "$36\r\nThis is a long string of length > 16\r\n",
 		},
+		{
+			name:     "Long SimpleString stays Simple String",
+			input:    SimpleString("This is a long status of length > 16"),
+			expected: "+This is a long status of length > 16\r\n",
+		},
+		{
+			name:     "Float needing more than six decimals",
+			input:    1e-9,
+			expected: ",1e-09\r\n",
+		},
+		{
+			name:     "Infinite Float",
+			input:    math.Inf(1),
+			expected: ",inf\r\n",
+		},
+		{
+			name:     "Integer json.Number",
+			input:    json.Number("9007199254740993"),
+			expected: ":9007199254740993\r\n",
+		},
+		{
+			name:     "Float json.Number",
+			input:    json.Number("2.5e1"),
+			expected: ",25.000000\r\n",
+		},
+		{
+			name:     "Verbatim String",
+			input:    VerbatimString{Format: "txt", Content: "Some string"},
+			expected: "=15\r\ntxt:Some string\r\n",
+		},
+		{
+			name:     "Empty Markdown Verbatim String",
+			input:    VerbatimString{Format: "mkd"},
+			expected: "=4\r\nmkd:\r\n",
+		},
 		{
 			name:     "Integer",
 			input:    123,
@@ -53,6 +97,28 @@ func TestEncode(t *testing.T) {
 			input:    errors.New("an error"),
 			expected: "-an error\r\n",
 		},
+		{
+			name:     "Wrapped Error",
+			input:    fmt.Errorf("write failed: %w", errors.New("disk full")),
+			expected: "-write failed: disk full\r\n",
+		},
+		{
+			name:     "RespError",
+			input:    &RespError{Code: "WRONGTYPE", Message: "Operation against a key"},
+			expected: "-WRONGTYPE Operation against a key\r\n",
+		},
+
+		// Bytes
+		{
+			name:     "Byte Slice",
+			input:    []byte("bin\x00ary"),
+			expected: "$7\r\nbin\x00ary\r\n",
+		},
+		{
+			name:     "Fixed Size Byte Array",
+			input:    [4]byte{'a', 0, 'b', '\n'},
+			expected: "$4\r\na\x00b\n\r\n",
+		},
 
 		// Arrays
 		{
@@ -81,6 +147,32 @@ func TestEncode(t *testing.T) {
 			expected: "*3\r\n,1.230000\r\n,4.560000\r\n,7.890000\r\n",
 		},
 
+		{
+			name:     "Array of Time",
+			input:    []time.Time{time.UnixMilli(1000), time.UnixMilli(2000)},
+			expected: "*2\r\n:1000\r\n:2000\r\n",
+		},
+		{
+			name:     "Fixed Size Array",
+			input:    [3]string{"x", "y", "z"},
+			expected: "*3\r\n+x\r\n+y\r\n+z\r\n",
+		},
+		{
+			name:     "Array of Structs",
+			input:    []RecordResponse{{Value: "v", Code: 1}},
+			expected: "*1\r\n%4\r\n+Value\r\n+v\r\n+Code\r\n:1\r\n",
+		},
+		{
+			name:     "Array of Struct Pointers",
+			input:    []*RecordResponse{{Value: 1.5, Code: 2}, nil},
+			expected: "*2\r\n%4\r\n+Value\r\n,1.500000\r\n+Code\r\n:2\r\n_\r\n",
+		},
+		{
+			name:     "Nested Typed Slices",
+			input:    [][]int{{1}, {2, 3}},
+			expected: "*2\r\n*1\r\n:1\r\n*2\r\n:2\r\n:3\r\n",
+		},
+
 		// Maps
 		{
 			name:     "Map with String Keys",
@@ -98,6 +190,39 @@ func TestEncode(t *testing.T) {
 			expected: "%6\r\n+a\r\n:1\r\n:2\r\n#t\r\n,3.140000\r\n+pi\r\n",
 		},
 
+		{
+			name:     "Typed Map with String Keys",
+			input:    map[string]int{"b": 2, "a": 1},
+			expected: "%4\r\n+a\r\n:1\r\n+b\r\n:2\r\n",
+		},
+		{
+			name:     "Typed Map with Int64 Keys",
+			input:    map[int64]string{2: "two", 1: "one"},
+			expected: "%4\r\n:1\r\n+one\r\n:2\r\n+two\r\n",
+		},
+		{
+			name:     "Typed Map with Struct Values",
+			input:    map[string]RecordResponse{"r": {Value: nil, Code: 3}},
+			expected: "%2\r\n+r\r\n%4\r\n+Value\r\n_\r\n+Code\r\n:3\r\n",
+		},
+
+		// Raw JSON
+		{
+			name:     "Raw JSON",
+			input:    json.RawMessage(`{"a":1}`),
+			expected: "$7\r\n{\"a\":1}\r\n",
+		},
+		{
+			name:     "Array of Raw JSON",
+			input:    []json.RawMessage{json.RawMessage(`[1,2]`), json.RawMessage(`"x"`)},
+			expected: "*2\r\n$5\r\n[1,2]\r\n$3\r\n\"x\"\r\n",
+		},
+		{
+			name:     "Map of Raw JSON",
+			input:    map[string]json.RawMessage{"b": json.RawMessage(`null`), "a": json.RawMessage(`{}`)},
+			expected: "%4\r\n+a\r\n$2\r\n{}\r\n+b\r\n$4\r\nnull\r\n",
+		},
+
 		// Structs
 		{
 			name: "Struct",
@@ -109,6 +234,99 @@ func TestEncode(t *testing.T) {
 			expected: "%6\r\n+Name\r\n+Alice\r\n+Post\r\n$24\r\nSenior Software Engineer\r\n+Age\r\n:25\r\n",
 		},
 
+		{
+			name:     "Zero Value Struct",
+			input:    ScalarRecord{},
+			expected: "%8\r\n+Value\r\n_\r\n+Type\r\n:0\r\n+LAT\r\n:0\r\n+Expiry\r\n:0\r\n",
+		},
+		{
+			name: "Struct with Unexported Field",
+			input: struct {
+				Name   string
+				hidden int
+			}{"Bob", 7},
+			expected: "%2\r\n+Name\r\n+Bob\r\n",
+		},
+
+		// database/sql null types
+		{
+			name:     "Valid sql.NullInt64",
+			input:    sql.NullInt64{Int64: 42, Valid: true},
+			expected: ":42\r\n",
+		},
+		{
+			name:     "Null sql.NullInt64",
+			input:    sql.NullInt64{},
+			expected: "_\r\n",
+		},
+		{
+			name:     "Valid sql.NullString",
+			input:    sql.NullString{String: "hello", Valid: true},
+			expected: "+hello\r\n",
+		},
+		{
+			name:     "Null sql.NullString",
+			input:    sql.NullString{String: "ignored"},
+			expected: "_\r\n",
+		},
+		{
+			name:     "Valid sql.NullBool",
+			input:    sql.NullBool{Bool: true, Valid: true},
+			expected: "#t\r\n",
+		},
+		{
+			name:     "Null sql.NullBool",
+			input:    sql.NullBool{},
+			expected: "_\r\n",
+		},
+		{
+			name:     "Valid sql.NullFloat64",
+			input:    sql.NullFloat64{Float64: 1.5, Valid: true},
+			expected: ",1.500000\r\n",
+		},
+		{
+			name:     "Valid sql.NullInt32",
+			input:    sql.NullInt32{Int32: 3, Valid: true},
+			expected: ":3\r\n",
+		},
+		{
+			name:     "Valid sql.NullTime",
+			input:    sql.NullTime{Time: time.UnixMilli(1500), Valid: true},
+			expected: ":1500\r\n",
+		},
+		{
+			name:     "Null sql.NullTime",
+			input:    sql.NullTime{},
+			expected: "_\r\n",
+		},
+		{
+			name:     "Valid generic sql.Null",
+			input:    sql.Null[string]{V: "x", Valid: true},
+			expected: "+x\r\n",
+		},
+		{
+			name:     "Pointer to sql.NullString",
+			input:    &sql.NullString{String: "p", Valid: true},
+			expected: "+p\r\n",
+		},
+		{
+			name:     "Nil pointer to sql.NullString",
+			input:    (*sql.NullString)(nil),
+			expected: "_\r\n",
+		},
+		{
+			name:     "Map of sql null values",
+			input:    map[string]interface{}{"a": sql.NullInt64{}},
+			expected: "%2\r\n+a\r\n_\r\n",
+		},
+
+		// Pushes
+		{
+			name:     "Push",
+			input:    PushMessage{"message", "news", []interface{}{1}},
+			expected: ">3\r\n+message\r\n+news\r\n*1\r\n:1\r\n",
+		},
+
 		// Time
 		{
 			name:     "Time",
@@ -119,7 +337,8 @@ func TestEncode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Encode(tt.input)
+			// Sorted keys make the expected output of multi-entry maps deterministic
+			got, err := EncodeSorted(tt.input)
 			if err != nil {
 				t.Fatalf("Encode() error = %v", err)
 			}
@@ -150,3 +369,1256 @@ func TestEncodeErrorCases(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeVerbatimStringInvalidFormat(t *testing.T) {
+	for _, format := range []string{"", "tx", "text"} {
+		_, err := Encode(VerbatimString{Format: format, Content: "hello"})
+		if err == nil {
+			t.Errorf("expected error for format %q, got none", format)
+		}
+	}
+}
+
+func TestEncodeVerbatimStringRoundTrip(t *testing.T) {
+	input := VerbatimString{Format: "txt", Content: "round trip"}
+
+	encoded, err := Encode(input)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	decoded, err := Decode(newReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if decoded != input {
+		t.Errorf("expected %v, got %v", input, decoded)
+	}
+}
+
+func TestEncoderSkipUnsupported(t *testing.T) {
+	input := map[string]interface{}{
+		"name":     "cfg",
+		"callback": func() {},
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.SkipUnsupported = true
+
+	if err := encoder.Encode(input); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	expected := "%2\r\n+name\r\n+cfg\r\n"
+	if sb.String() != expected {
+		t.Errorf("Encode() = %q, want %q", sb.String(), expected)
+	}
+
+	encoder.SkipUnsupported = false
+	if err := encoder.Encode(input); err == nil {
+		t.Errorf("expected error without SkipUnsupported, got none")
+	}
+}
+
+func BenchmarkEncodeLargeArray(b *testing.B) {
+	input := make([]interface{}, 10000)
+	for i := range input {
+		input[i] = i
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Encode(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEncoderMaxOutputSize(t *testing.T) {
+	input := make([]int, 1000)
+	for i := range input {
+		input[i] = i
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.MaxOutputSize = 256
+
+	err := encoder.Encode(input)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	if sb.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %d bytes", sb.Len())
+	}
+
+	if err := encoder.Encode(input[:10]); err != nil {
+		t.Errorf("expected small array to fit, got %v", err)
+	}
+}
+
+func TestEncodeUnsortedMapRoundTrip(t *testing.T) {
+	input := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}
+
+	encoded, err := Encode(input)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	decoded, err := Decode(newReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	expected := map[string]interface{}{"a": int64(1), "b": int64(2), "c": int64(3), "d": int64(4)}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+}
+
+func TestEncodeSorted(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "String keys",
+			input:    map[string]interface{}{"b": 2, "ab": 1, "a": 0},
+			expected: "%6\r\n+a\r\n:0\r\n+ab\r\n:1\r\n+b\r\n:2\r\n",
+		},
+		{
+			name:     "Mixed keys",
+			input:    map[interface{}]interface{}{true: 1, 10: 2, "z": 3, 2: 4, 1.5: 5, uint8(3): 6},
+			expected: "%12\r\n+z\r\n:3\r\n:2\r\n:4\r\n:3\r\n:6\r\n:10\r\n:2\r\n,1.500000\r\n:5\r\n#t\r\n:1\r\n",
+		},
+		{
+			name:     "Integer keys",
+			input:    map[int64]interface{}{10: "c", -3: "a", 2: "b", math.MinInt64: "min"},
+			expected: "%8\r\n:-9223372036854775808\r\n+min\r\n:-3\r\n+a\r\n:2\r\n+b\r\n:10\r\n+c\r\n",
+		},
+		{
+			name:     "Signed and unsigned integer keys",
+			input:    map[interface{}]interface{}{uint64(math.MaxUint64): 1, int64(-1): 2, uint8(0): 3},
+			expected: "%6\r\n:-1\r\n:2\r\n:0\r\n:3\r\n:18446744073709551615\r\n:1\r\n",
+		},
+		{
+			name: "Nested record maps",
+			input: []interface{}{map[int64]ScalarRecord{
+				2: {Value: "b", LAT: 2},
+				1: {Value: "a", LAT: 1},
+			}},
+			expected: "*1\r\n%4\r\n" +
+				":1\r\n%8\r\n+Value\r\n+a\r\n+Type\r\n:0\r\n+LAT\r\n:1\r\n+Expiry\r\n:0\r\n" +
+				":2\r\n%8\r\n+Value\r\n+b\r\n+Type\r\n:0\r\n+LAT\r\n:2\r\n+Expiry\r\n:0\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got, err := EncodeSorted(tt.input)
+				if err != nil {
+					t.Fatalf("EncodeSorted() error = %v", err)
+				}
+				if got != tt.expected {
+					t.Fatalf("EncodeSorted() = %q, want %q", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeNilVersusEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Nil interface slice", input: []interface{}(nil), expected: "_\r\n"},
+		{name: "Empty interface slice", input: []interface{}{}, expected: "*0\r\n"},
+		{name: "Nil string slice", input: []string(nil), expected: "_\r\n"},
+		{name: "Empty string slice", input: []string{}, expected: "*0\r\n"},
+		{name: "Nil int slice", input: []int(nil), expected: "_\r\n"},
+		{name: "Empty int slice", input: []int{}, expected: "*0\r\n"},
+		{name: "Nil struct slice", input: []ScalarRecord(nil), expected: "_\r\n"},
+		{name: "Nil string map", input: map[string]interface{}(nil), expected: "_\r\n"},
+		{name: "Empty string map", input: map[string]interface{}{}, expected: "%0\r\n"},
+		{name: "Nil typed map", input: map[string]int(nil), expected: "_\r\n"},
+		{name: "Empty typed map", input: map[string]int{}, expected: "%0\r\n"},
+		{name: "Nil nested slice", input: [][]int{nil, {}}, expected: "*2\r\n_\r\n*0\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Encode() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeUUIDRoundTrip(t *testing.T) {
+	uuid := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+
+	encoded, err := Encode(uuid)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if !strings.HasPrefix(encoded, "$16\r\n") {
+		t.Fatalf("expected a 16 byte bulk string, got %q", encoded)
+	}
+
+	decoder := NewDecoder(strings.NewReader(encoded))
+	decoder.RawBytes = true
+
+	decoded, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	var result [16]byte
+	copy(result[:], decoded.([]byte))
+	if result != uuid {
+		t.Errorf("expected %x, got %x", uuid, result)
+	}
+}
+
+func TestEncoderZeroTimeAsNull(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.ZeroTimeAsNull = true
+
+	if err := encoder.Encode(time.Time{}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if sb.String() != "_\r\n" {
+		t.Errorf("Encode() = %q, want %q", sb.String(), "_\r\n")
+	}
+
+	sb.Reset()
+	if err := encoder.Encode(time.UnixMilli(1620832335000)); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if sb.String() != ":1620832335000\r\n" {
+		t.Errorf("Encode() = %q, want %q", sb.String(), ":1620832335000\r\n")
+	}
+}
+
+func TestEncoderTimeFormat(t *testing.T) {
+	ts := time.Date(2021, 5, 12, 15, 12, 15, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   TimeFormat
+		expected string
+	}{
+		{"UnixMillis", UnixMillis, ":1620832335000\r\n"},
+		{"UnixSeconds", UnixSeconds, ":1620832335\r\n"},
+		{"RFC3339", RFC3339, "$20\r\n2021-05-12T15:12:15Z\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.TimeFormat = tt.format
+
+			if err := encoder.Encode(ts); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncoderTimeSlice(t *testing.T) {
+	times := []time.Time{
+		time.Date(2021, 5, 12, 15, 12, 15, 0, time.UTC),
+		time.Date(2021, 5, 12, 15, 12, 16, 500000000, time.UTC),
+		{},
+	}
+
+	for _, format := range []TimeFormat{UnixMillis, UnixSeconds, RFC3339} {
+		for _, zeroAsNull := range []bool{false, true} {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.TimeFormat = format
+			encoder.ZeroTimeAsNull = zeroAsNull
+
+			// Each element must match the encoding of the same time on its own
+			var expected strings.Builder
+			expected.WriteString("*3\r\n")
+			for _, ts := range times {
+				if err := encoder.Encode(ts); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+				expected.WriteString(sb.String())
+				sb.Reset()
+			}
+
+			if err := encoder.Encode(times); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != expected.String() {
+				t.Errorf("format %d: Encode() = %q, want %q", format, sb.String(), expected.String())
+			}
+		}
+	}
+
+	result, err := Encode(times[:2])
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if expected := "*2\r\n:1620832335000\r\n:1620832336500\r\n"; result != expected {
+		t.Errorf("Encode() = %q, want %q", result, expected)
+	}
+}
+
+func TestEncodeDuration(t *testing.T) {
+	result, err := Encode(1500 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if result != ":1500000000\r\n" {
+		t.Errorf("Encode() = %q, want %q", result, ":1500000000\r\n")
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.DurationAsString = true
+
+	if err := encoder.Encode(1500 * time.Millisecond); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if sb.String() != "$4\r\n1.5s\r\n" {
+		t.Errorf("Encode() = %q, want %q", sb.String(), "$4\r\n1.5s\r\n")
+	}
+}
+
+func TestEncodeBigFloat(t *testing.T) {
+	money, _, _ := big.ParseFloat("12345678901234567890.123456789", 10, 128, big.ToNearestEven)
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Exact value", input: big.NewFloat(0.5), expected: ",0.5\r\n"},
+		{name: "More digits than float64 holds", input: money, expected: ",1.2345678901234567890123456789e+19\r\n"},
+		{name: "Infinity", input: new(big.Float).SetInf(true), expected: ",-inf\r\n"},
+		{name: "Nil pointer", input: (*big.Float)(nil), expected: "_\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeBigFloatPreservesPrecision(t *testing.T) {
+	original, _, _ := big.ParseFloat("1234567890.0123456789012345678901", 10, 256, big.ToNearestEven)
+
+	encoded, err := Encode(original)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	decoder := NewDecoder(strings.NewReader(encoded))
+	decoder.UseBigFloat = true
+
+	result, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	decoded, ok := result.(*big.Float)
+	if !ok {
+		t.Fatalf("Decode() = %T, want *big.Float", result)
+	}
+	if decoded.Text('g', -1) != original.Text('g', -1) {
+		t.Errorf("Decode() = %s, want %s", decoded.Text('g', -1), original.Text('g', -1))
+	}
+}
+
+func TestEncodeAttributed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "Attributed value",
+			input:    Attributed{Attributes: map[string]interface{}{"ttl": 60}, Value: "v"},
+			expected: "|2\r\n+ttl\r\n:60\r\n+v\r\n",
+		},
+		{
+			name: "Attributed aggregate",
+			input: Attributed{
+				Attributes: map[string]interface{}{"key-popularity": []interface{}{"a", 0.5}},
+				Value:      []int{1, 2},
+			},
+			expected: "|2\r\n+key-popularity\r\n*2\r\n+a\r\n,0.500000\r\n*2\r\n:1\r\n:2\r\n",
+		},
+		{
+			name:     "Nested in an array",
+			input:    []interface{}{Attributed{Attributes: map[string]interface{}{"hot": true}, Value: 1}, 2},
+			expected: "*2\r\n|2\r\n+hot\r\n#t\r\n:1\r\n:2\r\n",
+		},
+		{
+			name:     "No attributes",
+			input:    Attributed{Value: "v"},
+			expected: "+v\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	_, err := Encode(Attributed{Attributes: map[string]interface{}{"bad": make(chan int)}, Value: 1})
+	if !errors.Is(err, ErrUnsupportedEncodeType) {
+		t.Errorf("Encode() error = %v, want ErrUnsupportedEncodeType", err)
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.Protocol = RESP2
+	if err := encoder.Encode(Attributed{Attributes: map[string]interface{}{"ttl": 60}, Value: "v"}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if sb.String() != "+v\r\n" {
+		t.Errorf("Encode() = %q, want %q", sb.String(), "+v\r\n")
+	}
+}
+
+func TestEncodeComplex(t *testing.T) {
+	type phasor complex128
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Complex128", input: complex(1.5, -2), expected: "*2\r\n,1.500000\r\n,-2.000000\r\n"},
+		{name: "Complex64", input: complex64(complex(0.1, 1)), expected: "*2\r\n,0.100000\r\n,1.000000\r\n"},
+		{name: "Named complex type", input: phasor(complex(0, 1e-9)), expected: "*2\r\n,0.000000\r\n,1e-09\r\n"},
+		{name: "Infinite part", input: complex(math.Inf(1), 0), expected: "*2\r\n,inf\r\n,0.000000\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+type temperature struct {
+	Celsius float64
+}
+
+func (t temperature) String() string {
+	return fmt.Sprintf("%.1fC", t.Celsius)
+}
+
+func TestEncoderStringers(t *testing.T) {
+	input := []interface{}{temperature{21.5}, &temperature{-3}, (*temperature)(nil), time.Second}
+
+	result, err := Encode(input)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	expected := "*4\r\n%2\r\n+Celsius\r\n,21.500000\r\n%2\r\n+Celsius\r\n,-3.000000\r\n_\r\n:1000000000\r\n"
+	if result != expected {
+		t.Errorf("Encode() = %q, want %q", result, expected)
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.Stringers = true
+
+	if err := encoder.Encode(input); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	// Explicitly supported types such as time.Duration keep their encoding
+	expected = "*4\r\n$5\r\n21.5C\r\n$5\r\n-3.0C\r\n_\r\n:1000000000\r\n"
+	if sb.String() != expected {
+		t.Errorf("Encode() = %q, want %q", sb.String(), expected)
+	}
+}
+
+func TestEncodeStringFormConsistent(t *testing.T) {
+	for _, str := range []string{"", "hello", "thirteen char", strings.Repeat("x", 16), strings.Repeat("x", 17)} {
+		standalone, err := Encode(str)
+		if err != nil {
+			t.Fatalf("Encode(%q) error = %v", str, err)
+		}
+
+		for _, input := range []interface{}{[]interface{}{str}, []string{str}} {
+			result, err := Encode(input)
+			if err != nil {
+				t.Fatalf("Encode(%#v) error = %v", input, err)
+			}
+			if expected := "*1\r\n" + standalone; result != expected {
+				t.Errorf("Encode(%#v) = %q, want %q", input, result, expected)
+			}
+		}
+	}
+}
+
+func TestEncoderSimpleStringMaxLen(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxLen   int
+		input    interface{}
+		expected string
+	}{
+		{name: "Default", input: []string{"0123456789abcdef", "0123456789abcdefg"}, expected: "*2\r\n+0123456789abcdef\r\n$17\r\n0123456789abcdefg\r\n"},
+		{name: "Raised", maxLen: 32, input: "0123456789abcdefg", expected: "+0123456789abcdefg\r\n"},
+		{name: "Lowered", maxLen: 2, input: []interface{}{"ab", "abc"}, expected: "*2\r\n+ab\r\n$3\r\nabc\r\n"},
+		{name: "Always bulk", maxLen: -1, input: []string{"SET", ""}, expected: "*2\r\n$3\r\nSET\r\n$0\r\n\r\n"},
+		{name: "CRLF overrides", maxLen: 32, input: "a\r\nb", expected: "$4\r\na\r\nb\r\n"},
+		{name: "CRLF overrides by default", input: []string{"a\nb"}, expected: "*1\r\n$3\r\na\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.SimpleStringMaxLen = tt.maxLen
+
+			if err := encoder.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeOrderedMap(t *testing.T) {
+	input := "%4\r\n+zeta\r\n:1\r\n+alpha\r\n:2\r\n"
+
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.PreserveOrder = true
+	decoded, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.SortKeys = true
+
+	if err := encoder.Encode(decoded); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if sb.String() != input {
+		t.Errorf("Encode() = %q, want %q", sb.String(), input)
+	}
+}
+
+func TestScalarRecordEncodeArray(t *testing.T) {
+	record := ScalarRecord{Value: "v", Type: 2, LAT: 1620832335, Expiry: -1}
+
+	asMap, err := Encode(record)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	expectedMap := "%8\r\n+Value\r\n+v\r\n+Type\r\n:2\r\n+LAT\r\n:1620832335\r\n+Expiry\r\n:-1\r\n"
+	if asMap != expectedMap {
+		t.Errorf("Encode() = %q, want %q", asMap, expectedMap)
+	}
+
+	asArray, err := record.EncodeArray()
+	if err != nil {
+		t.Fatalf("EncodeArray() error = %v", err)
+	}
+	expectedArray := "*4\r\n+v\r\n:2\r\n:1620832335\r\n:-1\r\n"
+	if asArray != expectedArray {
+		t.Errorf("EncodeArray() = %q, want %q", asArray, expectedArray)
+	}
+
+	decoded, err := Decode(bufio.NewReader(strings.NewReader(asArray)))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	var roundTripped ScalarRecord
+	if err := roundTripped.DecodeArray(decoded); err != nil {
+		t.Fatalf("DecodeArray() error = %v", err)
+	}
+	if roundTripped != record {
+		t.Errorf("DecodeArray() = %+v, want %+v", roundTripped, record)
+	}
+}
+
+func TestRecordResponseEncodeArray(t *testing.T) {
+	record := RecordResponse{Value: int64(7), Code: 3}
+
+	asArray, err := record.EncodeArray()
+	if err != nil {
+		t.Fatalf("EncodeArray() error = %v", err)
+	}
+	if asArray != "*2\r\n:7\r\n:3\r\n" {
+		t.Errorf("EncodeArray() = %q, want %q", asArray, "*2\r\n:7\r\n:3\r\n")
+	}
+
+	decoded, err := Decode(bufio.NewReader(strings.NewReader(asArray)))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	var roundTripped RecordResponse
+	if err := roundTripped.DecodeArray(decoded); err != nil {
+		t.Fatalf("DecodeArray() error = %v", err)
+	}
+	if roundTripped != record {
+		t.Errorf("DecodeArray() = %+v, want %+v", roundTripped, record)
+	}
+
+	if err := roundTripped.DecodeArray([]interface{}{int64(7), int64(-1)}); !errors.Is(err, ErrProtocol) {
+		t.Errorf("DecodeArray() error = %v, want ErrProtocol", err)
+	}
+}
+
+func TestEncoderRESP2(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Map", input: map[string]interface{}{"a": 1}, expected: "*2\r\n+a\r\n:1\r\n"},
+		{name: "Struct", input: struct{ A bool }{A: true}, expected: "*2\r\n+A\r\n:1\r\n"},
+		{name: "True", input: true, expected: ":1\r\n"},
+		{name: "False", input: false, expected: ":0\r\n"},
+		{name: "Float", input: 3.14, expected: "$8\r\n3.140000\r\n"},
+		{name: "Verbatim string", input: VerbatimString{Format: "txt", Content: "hi"}, expected: "$2\r\nhi\r\n"},
+		{name: "Nil", input: nil, expected: "$-1\r\n"},
+		{name: "Nil pointer", input: (*int)(nil), expected: "$-1\r\n"},
+		{name: "Nil slice", input: []int(nil), expected: "*-1\r\n"},
+		{name: "Nil map", input: map[string]int(nil), expected: "*-1\r\n"},
+		{name: "Nested", input: []interface{}{false, nil}, expected: "*2\r\n:0\r\n$-1\r\n"},
+		{name: "Push", input: PushMessage{"message", true}, expected: "*2\r\n+message\r\n:1\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.Protocol = RESP2
+
+			if err := encoder.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeSimpleStringWithCRLF(t *testing.T) {
+	if _, err := Encode(SimpleString("OK\r\n+INJECTED")); err == nil {
+		t.Errorf("expected an error for a simple string containing CRLF")
+	}
+}
+
+func TestEncodeRuneAndByte(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{name: "Rune", input: 'A', expected: ":65\r\n"},
+		{name: "Byte", input: byte('A'), expected: ":65\r\n"},
+		{name: "Rune as string", input: string('A'), expected: "+A\r\n"},
+		{name: "Rune slice", input: []rune("hi"), expected: "*2\r\n:104\r\n:105\r\n"},
+		{name: "Byte slice", input: []byte("hi"), expected: "$2\r\nhi\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeCommand(t *testing.T) {
+	expected := "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n"
+
+	if result := EncodeCommand("SET", "key", "value"); result != expected {
+		t.Errorf("EncodeCommand(strings) = %q, want %q", result, expected)
+	}
+
+	if result := EncodeCommand([]byte("SET"), []byte("key"), []byte("value")); result != expected {
+		t.Errorf("EncodeCommand(bytes) = %q, want %q", result, expected)
+	}
+
+	result, err := Encode([][]byte{[]byte("SET"), []byte("key"), []byte("value")})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if result != expected {
+		t.Errorf("Encode([][]byte) = %q, want %q", result, expected)
+	}
+}
+
+func TestEncodeStructSlices(t *testing.T) {
+	first := "%8\r\n+Value\r\n+a\r\n+Type\r\n:1\r\n+LAT\r\n:10\r\n+Expiry\r\n:-1\r\n"
+	second := "%8\r\n+Value\r\n:2\r\n+Type\r\n:0\r\n+LAT\r\n:20\r\n+Expiry\r\n:0\r\n"
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "Struct slice",
+			input:    []ScalarRecord{{Value: "a", Type: 1, LAT: 10, Expiry: -1}, {Value: 2, LAT: 20}},
+			expected: "*2\r\n" + first + second,
+		},
+		{
+			name:     "Struct pointer slice",
+			input:    []*ScalarRecord{{Value: "a", Type: 1, LAT: 10, Expiry: -1}, nil, {Value: 2, LAT: 20}},
+			expected: "*3\r\n" + first + "_\r\n" + second,
+		},
+		{
+			name:     "Empty struct slice",
+			input:    []ScalarRecord{},
+			expected: "*0\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeCanonical(t *testing.T) {
+	first := map[string]interface{}{}
+	first["zeta"] = map[string]interface{}{"b": 2, "a": 1}
+	first["alpha"] = map[interface{}]interface{}{3: "x", "k": "y"}
+
+	second := map[string]interface{}{}
+	second["alpha"] = map[interface{}]interface{}{"k": "y", 3: "x"}
+	second["zeta"] = map[string]interface{}{"a": 1, "b": 2}
+
+	expected := "%4\r\n" +
+		"$5\r\nalpha\r\n%4\r\n$1\r\nk\r\n+y\r\n:3\r\n+x\r\n" +
+		"$4\r\nzeta\r\n%4\r\n$1\r\na\r\n:1\r\n$1\r\nb\r\n:2\r\n"
+
+	for i := 0; i < 20; i++ {
+		for _, input := range []map[string]interface{}{first, second} {
+			got, err := EncodeCanonical(input)
+			if err != nil {
+				t.Fatalf("EncodeCanonical() error = %v", err)
+			}
+			if got != expected {
+				t.Fatalf("EncodeCanonical() = %q, want %q", got, expected)
+			}
+		}
+	}
+}
+
+func TestEncoderBulkStringKeys(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.BulkStringKeys = true
+
+	if err := encoder.Encode(RecordResponse{Value: "v", Code: 1}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	expected := "%4\r\n$5\r\nValue\r\n+v\r\n$4\r\nCode\r\n:1\r\n"
+	if sb.String() != expected {
+		t.Errorf("Encode() = %q, want %q", sb.String(), expected)
+	}
+}
+
+func TestEncodeCommonMapShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "String list values",
+			input:    map[string][]string{"Accept": {"text/plain", "a much longer media type"}, "Empty": {}, "Absent": nil},
+			expected: "%6\r\n+Absent\r\n_\r\n+Accept\r\n*2\r\n+text/plain\r\n$24\r\na much longer media type\r\n+Empty\r\n*0\r\n",
+		},
+		{
+			name:     "Counters",
+			input:    map[string]int{"hits": 42, "misses": -1, "zero": 0},
+			expected: "%6\r\n+hits\r\n:42\r\n+misses\r\n:-1\r\n+zero\r\n:0\r\n",
+		},
+		{
+			name:     "Empty counters",
+			input:    map[string]int{},
+			expected: "%0\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EncodeSorted(tt.input)
+			if err != nil {
+				t.Fatalf("EncodeSorted() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("EncodeSorted() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	result, err := Encode(map[string]int{"n": 1})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if result != "%2\r\n+n\r\n:1\r\n" {
+		t.Errorf("Encode() = %q, want %q", result, "%2\r\n+n\r\n:1\r\n")
+	}
+}
+
+func TestEncodeMapKeyWithCRLF(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "Key with a newline",
+			input:    map[string]interface{}{"a\nb": 1},
+			expected: "%2\r\n$3\r\na\nb\r\n:1\r\n",
+		},
+		{
+			name:     "Key injecting a frame",
+			input:    map[string]string{"k\r\n+injected": "v"},
+			expected: "%2\r\n$12\r\nk\r\n+injected\r\n+v\r\n",
+		},
+		{
+			name:     "Ordered map key",
+			input:    OrderedMap{{Key: "x\r", Value: true}},
+			expected: "%2\r\n$2\r\nx\r\r\n#t\r\n",
+		},
+		{
+			name:     "Long key stays a simple string",
+			input:    map[string]interface{}{"a key longer than sixteen bytes": 1},
+			expected: "%2\r\n+a key longer than sixteen bytes\r\n:1\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+
+			// The key must decode back intact
+			if _, err := Decode(newReader(result)); err != nil {
+				t.Errorf("Decode() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeArrayErrorIndex(t *testing.T) {
+	_, err := Encode([]interface{}{1, "a", true, make(chan int)})
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+
+	expected := "encode index 3: unsupported type chan int, convert the value to a supported type before encoding it: UnsupportedEncodeType"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	if !errors.Is(err, ErrUnsupportedEncodeType) {
+		t.Errorf("expected ErrUnsupportedEncodeType, got %v", err)
+	}
+}
+
+func TestEncodeUnencodableKinds(t *testing.T) {
+	var nilFunc func()
+	var target int
+
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{name: "Func", input: func() {}},
+		{name: "Nil func", input: nilFunc},
+		{name: "Chan", input: make(chan int)},
+		{name: "Unsafe pointer", input: unsafe.Pointer(&target)},
+		{name: "Nested", input: map[string]interface{}{"f": func() {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Encode(tt.input)
+			if !errors.Is(err, ErrUnsupportedEncodeType) {
+				t.Fatalf("expected ErrUnsupportedEncodeType, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "convert the value to a supported type") {
+				t.Errorf("expected a hint to convert the value, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestEncoderSkipUnsupportedArray(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.SkipUnsupported = true
+
+	if err := encoder.Encode([]interface{}{1, make(chan int), "a", func() {}}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	expected := "*2\r\n:1\r\n+a\r\n"
+	if sb.String() != expected {
+		t.Errorf("Encode() = %q, want %q", sb.String(), expected)
+	}
+}
+
+func BenchmarkEncodeCommandStrings(b *testing.B) {
+	args := []string{"SET", "user:1000:session", "a value long enough to be sent as a bulk string"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EncodeCommand(args...)
+	}
+}
+
+func BenchmarkEncodeCommandBytes(b *testing.B) {
+	args := [][]byte{[]byte("SET"), []byte("user:1000:session"), []byte("a value long enough to be sent as a bulk string")}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EncodeCommand(args...)
+	}
+}
+
+func TestEncodeErrorCollections(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			name:     "Error slice",
+			input:    []error{errors.New("ERR first"), &RespError{Code: "WRONGTYPE", Message: "bad key"}},
+			expected: "*2\r\n-ERR first\r\n-WRONGTYPE bad key\r\n",
+		},
+		{
+			name:     "Error slice with nil",
+			input:    []error{nil, fmt.Errorf("ERR wrapped: %w", ErrProtocol)},
+			expected: "*2\r\n_\r\n-ERR wrapped: " + ErrProtocol.Error() + "\r\n",
+		},
+		{
+			name:     "Empty error slice",
+			input:    []error{},
+			expected: "*0\r\n",
+		},
+		{
+			name:     "Error map",
+			input:    map[string]error{"k1": errors.New("ERR no such key"), "k2": nil},
+			expected: "%4\r\n+k1\r\n-ERR no such key\r\n+k2\r\n_\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EncodeSorted(tt.input)
+			if err != nil {
+				t.Fatalf("EncodeSorted() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("EncodeSorted() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeErrorWithControlBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    error
+		protocol Protocol
+		expected string
+	}{
+		{"Plain message", errors.New("ERR plain"), RESP3, "-ERR plain\r\n"},
+		{"Multiline message", errors.New("bad\r\ninjected"), RESP3, "!13\r\nbad\r\ninjected\r\n"},
+		{"Injected frame", errors.New("ERR x\r\n+OK"), RESP3, "!10\r\nERR x\r\n+OK\r\n"},
+		{"Tab", errors.New("ERR a\tb"), RESP3, "!7\r\nERR a\tb\r\n"},
+		{"Multiline message under RESP2", errors.New("bad\r\ninjected"), RESP2, "-bad  injected\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.Protocol = tt.protocol
+			if err := encoder.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+
+	// The blob error decodes back to the whole message as a single reply
+	encoded, err := Encode(errors.New("ERR bad\r\ninjected"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	reader := newReader(encoded)
+	decoded, err := Decode(reader)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if respErr, ok := decoded.(*RespError); !ok || respErr.Error() != "ERR bad\r\ninjected" {
+		t.Errorf("expected the whole message back, got %#v", decoded)
+	}
+	if reader.Buffered() != 0 {
+		t.Errorf("expected no trailing frames, %d bytes left", reader.Buffered())
+	}
+}
+
+func TestEncodeBlobError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		protocol Protocol
+		expected string
+	}{
+		{"Short message", BlobError("ERR boom"), RESP3, "!8\r\nERR boom\r\n"},
+		{"Empty message", BlobError(""), RESP3, "!0\r\n\r\n"},
+		{"Multiline message", BlobError("SYNTAX a\r\nb"), RESP3, "!11\r\nSYNTAX a\r\nb\r\n"},
+		{"In an array", []interface{}{BlobError("ERR x"), errors.New("ERR y")}, RESP3, "*2\r\n!5\r\nERR x\r\n-ERR y\r\n"},
+		{"Under RESP2", BlobError("ERR a\nb"), RESP2, "-ERR a b\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.Protocol = tt.protocol
+			if err := encoder.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+
+	decoded, err := Decode(newReader("!8\r\nERR boom\r\n"))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	encoded, err := Encode(BlobError(decoded.(*RespError).Error()))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if encoded != "!8\r\nERR boom\r\n" {
+		t.Errorf("Encode() = %q, want %q", encoded, "!8\r\nERR boom\r\n")
+	}
+}
+
+func TestEncoderEncodeStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []interface{}
+		protocol Protocol
+		expected string
+	}{
+		{"Elements", []interface{}{"a", 1, []int{2}}, RESP3, "*?\r\n+a\r\n:1\r\n*1\r\n:2\r\n.\r\n"},
+		{"Empty", nil, RESP3, "*?\r\n.\r\n"},
+		{"Under RESP2", []interface{}{"a", true}, RESP2, "*2\r\n+a\r\n:1\r\n"},
+		{"Empty under RESP2", nil, RESP2, "*0\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan interface{})
+			go func() {
+				defer close(ch)
+				for _, elem := range tt.input {
+					ch <- elem
+				}
+			}()
+
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.Protocol = tt.protocol
+			if err := encoder.EncodeStream(ch); err != nil {
+				t.Fatalf("EncodeStream() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("EncodeStream() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncoderEncodeStreamRoundTrip(t *testing.T) {
+	ch := make(chan interface{}, 3)
+	ch <- "x"
+	ch <- int64(7)
+	ch <- nil
+	close(ch)
+
+	var sb strings.Builder
+	if err := NewEncoder(&sb).EncodeStream(ch); err != nil {
+		t.Fatalf("EncodeStream() error = %v", err)
+	}
+
+	decoded, err := Decode(newReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := []interface{}{"x", int64(7), nil}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("expected %#v, got %#v", want, decoded)
+	}
+}
+
+func TestEncoderEncodeStreamUnsupportedElement(t *testing.T) {
+	ch := make(chan interface{}, 2)
+	ch <- "ok"
+	ch <- func() {}
+	close(ch)
+
+	var sb strings.Builder
+	if err := NewEncoder(&sb).EncodeStream(ch); !errors.Is(err, ErrUnsupportedEncodeType) {
+		t.Errorf("expected ErrUnsupportedEncodeType, got %v", err)
+	}
+
+	// SkipUnsupported does not apply to the elements under either protocol
+	for _, protocol := range []Protocol{RESP3, RESP2} {
+		ch := make(chan interface{}, 2)
+		ch <- "ok"
+		ch <- func() {}
+		close(ch)
+
+		var sb strings.Builder
+		encoder := NewEncoder(&sb)
+		encoder.Protocol = protocol
+		encoder.SkipUnsupported = true
+		if err := encoder.EncodeStream(ch); !errors.Is(err, ErrUnsupportedEncodeType) {
+			t.Errorf("expected ErrUnsupportedEncodeType under protocol %v, got %v", protocol, err)
+		}
+	}
+
+	ch = make(chan interface{}, 1)
+	ch <- func() {}
+	close(ch)
+
+	sb.Reset()
+	encoder := NewEncoder(&sb)
+	encoder.Protocol = RESP2
+	if err := encoder.EncodeStream(ch); !errors.Is(err, ErrUnsupportedEncodeType) || sb.Len() != 0 {
+		t.Errorf("expected ErrUnsupportedEncodeType with nothing written under RESP2, got %v and %q", err, sb.String())
+	}
+}
+
+func TestEncodeMulti(t *testing.T) {
+	cmds := [][]string{
+		{"SET", "key", "value"},
+		{"INCR", "counter"},
+		{"GET", "key"},
+	}
+	expected := EncodeCommand("SET", "key", "value") + EncodeCommand("INCR", "counter") + EncodeCommand("GET", "key")
+
+	result, err := EncodeMulti(cmds)
+	if err != nil {
+		t.Fatalf("EncodeMulti() error = %v", err)
+	}
+	if result != expected {
+		t.Errorf("EncodeMulti() = %q, want %q", result, expected)
+	}
+
+	var sb strings.Builder
+	if err := NewEncoder(&sb).WriteMulti(cmds); err != nil {
+		t.Fatalf("WriteMulti() error = %v", err)
+	}
+	if sb.String() != expected {
+		t.Errorf("WriteMulti() = %q, want %q", sb.String(), expected)
+	}
+
+	// The pipeline decodes as three separate commands
+	reader := newReader(result)
+	for i, cmd := range cmds {
+		decoded, err := DecodeStringSlice(reader)
+		if err != nil {
+			t.Fatalf("command %d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded, cmd) {
+			t.Errorf("command %d: expected %q, got %q", i, cmd, decoded)
+		}
+	}
+
+	if result, err := EncodeMulti(nil); err != nil || result != "" {
+		t.Errorf("EncodeMulti(nil) = (%q, %v), want empty output", result, err)
+	}
+
+	sb.Reset()
+	if err := NewEncoder(&sb).WriteMulti([][]string{{"PING"}, {}}); err == nil {
+		t.Errorf("expected error for an empty command, got none")
+	}
+	if sb.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", sb.String())
+	}
+}
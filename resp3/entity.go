@@ -1,13 +1,197 @@
 package resp3
 
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
 type RecordResponse struct {
 	Value interface{}
 	Code  uint32
 }
 
+// EncodeArray encodes r positionally as the RESP3 array [Value, Code] rather
+// than as the map of field names produced by Encode.
+func (r *RecordResponse) EncodeArray() (string, error) {
+	return Encode([]interface{}{r.Value, r.Code})
+}
+
+// DecodeArray fills r from a decoded array in the layout written by EncodeArray.
+func (r *RecordResponse) DecodeArray(value interface{}) error {
+	fields, err := positionalFields(value, 2)
+	if err != nil {
+		return err
+	}
+
+	code, err := positionalInt(fields[1], 0, math.MaxUint32)
+	if err != nil {
+		return err
+	}
+
+	r.Value, r.Code = fields[0], uint32(code)
+	return nil
+}
+
 type ScalarRecord struct {
 	Value  interface{}
 	Type   uint8
 	LAT    int64
 	Expiry int64
 }
+
+// EncodeArray encodes r positionally as the RESP3 array [Value, Type, LAT, Expiry]
+// rather than as the map of field names produced by Encode.
+func (r *ScalarRecord) EncodeArray() (string, error) {
+	return Encode([]interface{}{r.Value, r.Type, r.LAT, r.Expiry})
+}
+
+// DecodeArray fills r from a decoded array in the layout written by EncodeArray.
+func (r *ScalarRecord) DecodeArray(value interface{}) error {
+	fields, err := positionalFields(value, 4)
+	if err != nil {
+		return err
+	}
+
+	typ, err := positionalInt(fields[1], 0, math.MaxUint8)
+	if err != nil {
+		return err
+	}
+	lat, err := positionalInt(fields[2], math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	expiry, err := positionalInt(fields[3], math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	r.Value, r.Type, r.LAT, r.Expiry = fields[0], uint8(typ), lat, expiry
+	return nil
+}
+
+// positionalFields returns the elements of a decoded array, which must hold exactly n of them.
+func positionalFields(value interface{}, n int) ([]interface{}, error) {
+	fields, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array, got %T: %w", value, ErrProtocol)
+	}
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d fields, got %d: %w", n, len(fields), ErrProtocol)
+	}
+	return fields, nil
+}
+
+// positionalInt returns a decoded integer field, which must lie within [min, max].
+func positionalInt(value interface{}, min, max int64) (int64, error) {
+	i, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("expected an integer field, got %T: %w", value, ErrProtocol)
+	}
+	if i < min || i > max {
+		return 0, fmt.Errorf("integer field %d out of range: %w", i, ErrProtocol)
+	}
+	return i, nil
+}
+
+// VerbatimString is a RESP3 verbatim string ("=15\r\ntxt:Some string\r\n").
+// Format is the three-byte format hint such as "txt" or "mkd", and Content is
+// the payload following the "<format>:" prefix.
+type VerbatimString struct {
+	Format  string
+	Content string
+}
+
+// newVerbatimString splits a verbatim string body into its format and content.
+// Bodies lacking a well-formed "xxx:" prefix are kept whole as the content.
+func newVerbatimString(body string) VerbatimString {
+	if !hasVerbatimFormat([]byte(body)) {
+		return VerbatimString{Content: body}
+	}
+	return VerbatimString{Format: body[:3], Content: body[4:]}
+}
+
+// hasVerbatimFormat reports whether a verbatim string body starts with a "xxx:" prefix.
+func hasVerbatimFormat(body []byte) bool {
+	return len(body) >= 4 && body[3] == ':'
+}
+
+// SimpleString is a RESP3 simple string ("+OK\r\n"), such as a status reply. It
+// is returned by a Decoder with SimpleStrings set and is always encoded as a
+// simple string, whatever its length.
+type SimpleString string
+
+// IsStatus reports whether v is a status reply, a simple string such as "OK",
+// "QUEUED" or "PONG", and returns its text. Decode returns simple and bulk
+// strings alike as string, so statuses are only recognized in values decoded by
+// a Decoder with SimpleStrings set, which returns them as SimpleString, or as a
+// Value of type TypeSimpleString. A bulk string reading "OK" is never a status.
+//
+// Example usage:
+//
+//	decoder.SimpleStrings = true
+//	reply, _ := decoder.Decode()
+//	if status, ok := IsStatus(reply); ok && status == "QUEUED" {
+//	    // The command was queued in the transaction
+//	}
+func IsStatus(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case SimpleString:
+		return string(s), true
+	case Value:
+		if s.Type == TypeSimpleString {
+			return s.Str, true
+		}
+	}
+	return "", false
+}
+
+// Set is the decoded form of a RESP3 set ("~"). Each distinct element is stored
+// as a key, so elements must be hashable.
+type Set map[interface{}]struct{}
+
+// Attributed is a value sent with RESP3 attributes ("|"), out-of-band metadata
+// such as popularity hints or TTLs that a client may use or ignore. Encode writes
+// the attributes as a "|" map immediately before the encoded Value, and Decode
+// returns an attributed reply as an Attributed holding both. A Decoder with
+// Values set does not support attributes.
+type Attributed struct {
+	Attributes map[string]interface{}
+	Value      interface{}
+}
+
+// PushMessage is the decoded form of a RESP3 push (">"), out-of-band data such as
+// pub/sub messages or client-side caching invalidations that a server sends
+// independently of replies. It holds the elements of the push, the first of
+// which is conventionally its kind, such as "message".
+type PushMessage []interface{}
+
+// KeyValue is a single entry of an OrderedMap.
+type KeyValue struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// OrderedMap is a RESP3 map ("%") kept as a list of entries in the order they
+// appeared on the wire, for replies whose field order is meaningful. It is
+// returned by a Decoder with PreserveOrder set and encodes back in the same order.
+type OrderedMap []KeyValue
+
+// Get returns the value stored under key and whether the key is present.
+func (m OrderedMap) Get(key interface{}) (interface{}, bool) {
+	for _, kv := range m {
+		if reflect.DeepEqual(kv.Key, key) {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of m in order.
+func (m OrderedMap) Keys() []interface{} {
+	keys := make([]interface{}, len(m))
+	for i, kv := range m {
+		keys[i] = kv.Key
+	}
+	return keys
+}
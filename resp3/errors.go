@@ -1,7 +1,94 @@
 package resp3
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
+// Sentinel errors returned by the package. Errors are usually wrapped with
+// details such as the offending value or the offset in the stream, so they
+// should be matched with errors.Is rather than compared directly.
 var (
+	// ErrUnsupportedRespDataType is returned when decoding a frame whose type
+	// marker is unknown, or not allowed by the Decoder's options.
 	ErrUnsupportedRespDataType = errors.New("UnsupportedRespDataType")
+
+	// ErrProtocol is returned when decoding malformed input, such as an invalid
+	// length or number, a missing CRLF or an aggregate of the wrong shape.
+	ErrProtocol = errors.New("ProtocolError")
+
+	// ErrLimitExceeded is returned when a configured limit, such as the
+	// Encoder's MaxOutputSize or the Decoder's MaxDepth, is exceeded.
+	ErrLimitExceeded = errors.New("LimitExceeded")
+
+	// ErrMaxDepthExceeded is returned when decoded aggregates are nested deeper
+	// than the Decoder's MaxDepth. Such errors match ErrLimitExceeded as well.
+	ErrMaxDepthExceeded = errors.New("MaxDepthExceeded")
+
+	// ErrUnsupportedEncodeType is returned when encoding a value of a type that
+	// has no RESP3 representation, such as a chan or a func.
+	ErrUnsupportedEncodeType = errors.New("UnsupportedEncodeType")
 )
+
+// RespError is the value produced when decoding RESP3 simple errors ("-") and
+// blob errors ("!"). By convention the first word of a RESP error is a
+// machine-readable code (ERR, WRONGTYPE, MOVED, ASK, ...), which is exposed
+// separately so callers can branch on it without parsing Error().
+//
+// Example:
+//
+//	"-WRONGTYPE Operation against a key\r\n" -> &RespError{Code: "WRONGTYPE", Message: "Operation against a key"}
+type RespError struct {
+	Code    string
+	Message string
+}
+
+// Error returns the full error text as it appeared on the wire.
+func (e *RespError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Code + " " + e.Message
+}
+
+// BlobError is an error that Encode always writes as a RESP3 blob error
+// ("!<len>\r\n<text>\r\n"), which carries arbitrary text, such as long or
+// multiline messages, safely. Other errors are written as simple errors ("-")
+// unless their text holds control bytes. Under RESP2, which lacks blob errors,
+// it is written as a simple error with each control byte replaced by a space.
+//
+// Example:
+//
+//	BlobError("SYNTAX invalid\r\nline 2") -> "!22\r\nSYNTAX invalid\r\nline 2\r\n"
+type BlobError string
+
+// Error returns the error text.
+func (e BlobError) Error() string {
+	return string(e)
+}
+
+// newRespError splits a raw RESP error string into its code and message.
+func newRespError(s string) *RespError {
+	code, message, _ := strings.Cut(s, " ")
+	return &RespError{Code: code, Message: message}
+}
+
+// DecodeError wraps a failure to decode malformed input with the position in the
+// stream at which it was detected, which helps locating where a connection got
+// out of sync. The wrapped error remains reachable through errors.Is and errors.As.
+type DecodeError struct {
+	Offset int64 // Bytes consumed by the Decoder when the error was detected
+	Type   byte  // Type marker of the innermost frame being parsed
+	Err    error
+}
+
+// Error describes the failure together with its frame type and offset.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %q frame at offset %d: %v", e.Type, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,115 @@
+package resp3
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// ReadArrayHeader consumes only the "*<n>\r\n" header of an array reply, so its
+// elements can be processed one at a time as they arrive, by calling Decode count
+// times, instead of being buffered into a []interface{}. It is the decoding
+// counterpart of Encoder.WriteArrayHeader.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - count int: The number of elements that follow the header.
+//   - isNull bool: Whether the reply is the null array "*-1" or the RESP3 null "_",
+//     neither of which has elements.
+//   - err error: The decoded *RespError if the reply is an error reply, an error
+//     wrapping ErrProtocol if the reply is not an array or is a streamed array
+//     ("*?"), whose count is unknown, or a decoding error. A reply of another
+//     type is consumed entirely before returning.
+//
+// Example usage:
+//
+//	count, isNull, err := ReadArrayHeader(reader) // "*2\r\n:1\r\n:2\r\n" -> 2, false, nil
+//	for i := 0; i < count; i++ {
+//	    element, err := Decode(reader)
+//	    // Process the element
+//	}
+func ReadArrayHeader(reader *bufio.Reader) (count int, isNull bool, err error) {
+	return (&Decoder{reader: reader}).ReadArrayHeader()
+}
+
+// ReadMapHeader consumes only the "%<n>\r\n" header of a map reply, see
+// ReadArrayHeader. The count returned is the number of entries, each of which
+// the caller reads as a key followed by its value, whereas the count on the wire
+// covers keys and values alike.
+func ReadMapHeader(reader *bufio.Reader) (count int, isNull bool, err error) {
+	return (&Decoder{reader: reader}).ReadMapHeader()
+}
+
+// ReadSetHeader consumes only the "~<n>\r\n" header of a set reply, see
+// ReadArrayHeader.
+func ReadSetHeader(reader *bufio.Reader) (count int, isNull bool, err error) {
+	return (&Decoder{reader: reader}).ReadSetHeader()
+}
+
+// ReadArrayHeader consumes the header of the next reply, which must be an array;
+// see the package-level ReadArrayHeader function.
+func (d *Decoder) ReadArrayHeader() (count int, isNull bool, err error) {
+	return d.readHeader('*')
+}
+
+// ReadMapHeader consumes the header of the next reply, which must be a map; see
+// the package-level ReadMapHeader function.
+func (d *Decoder) ReadMapHeader() (count int, isNull bool, err error) {
+	count, isNull, err = d.readHeader('%')
+	if err != nil || isNull {
+		return count, isNull, err
+	}
+	if count%2 != 0 {
+		return 0, false, d.frameError('%', fmt.Errorf("map has a key without a value: %w", ErrProtocol))
+	}
+	return count / 2, false, nil
+}
+
+// ReadSetHeader consumes the header of the next reply, which must be a set; see
+// the package-level ReadSetHeader function.
+func (d *Decoder) ReadSetHeader() (count int, isNull bool, err error) {
+	return d.readHeader('~')
+}
+
+// readHeader reads the header of an aggregate reply of the type marked by want.
+func (d *Decoder) readHeader(want byte) (count int, isNull bool, err error) {
+	start := d.offset
+	defer func() { d.lastFrameSize = int(d.offset - start) }()
+
+	dataType, err := d.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if dataType != want {
+		// Consume the whole reply so the stream stays aligned
+		value, err := d.decodeFrame(dataType)
+		if err != nil {
+			return 0, false, d.frameError(dataType, err)
+		}
+		if respErr, ok := value.(*RespError); ok {
+			return 0, false, respErr
+		}
+		if dataType == '_' {
+			return 0, true, nil // RESP3 null, written for missing values like "*-1"
+		}
+		return 0, false, d.replyError(dataType, fmt.Errorf("expected %s header, got %T: %w", RespType(want), value, ErrProtocol))
+	}
+
+	if err := d.checkType(dataType); err != nil {
+		return 0, false, d.frameError(dataType, err)
+	}
+
+	count, streamed, err := d.readCount()
+	if err != nil {
+		return 0, false, d.frameError(dataType, err)
+	}
+	if streamed {
+		return 0, false, d.frameError(dataType, fmt.Errorf("streamed %s has no element count: %w", RespType(want), ErrProtocol))
+	}
+	if count == -1 {
+		return 0, true, nil
+	}
+	return count, false, nil
+}
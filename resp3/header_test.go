@@ -0,0 +1,80 @@
+package resp3
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReadAggregateHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		read       func(d *Decoder) (int, bool, error)
+		wantCount  int
+		wantIsNull bool
+		wantElems  []interface{}
+	}{
+		{"array", "*2\r\n:1\r\n+two\r\n", (*Decoder).ReadArrayHeader, 2, false, []interface{}{int64(1), "two"}},
+		{"empty array", "*0\r\n", (*Decoder).ReadArrayHeader, 0, false, nil},
+		{"null array", "*-1\r\n", (*Decoder).ReadArrayHeader, 0, true, nil},
+		{"map", "%4\r\n+a\r\n:1\r\n+b\r\n:2\r\n", (*Decoder).ReadMapHeader, 2, false, []interface{}{"a", int64(1), "b", int64(2)}},
+		{"null map", "%-1\r\n", (*Decoder).ReadMapHeader, 0, true, nil},
+		{"RESP3 null as array", "_\r\n", (*Decoder).ReadArrayHeader, 0, true, nil},
+		{"RESP3 null as map", "_\r\n", (*Decoder).ReadMapHeader, 0, true, nil},
+		{"RESP3 null as set", "_\r\n", (*Decoder).ReadSetHeader, 0, true, nil},
+		{"set", "~1\r\n#t\r\n", (*Decoder).ReadSetHeader, 1, false, []interface{}{true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(newReader(tt.input))
+			count, isNull, err := tt.read(decoder)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != tt.wantCount || isNull != tt.wantIsNull {
+				t.Fatalf("expected (%d, %t), got (%d, %t)", tt.wantCount, tt.wantIsNull, count, isNull)
+			}
+
+			// The elements are left for the caller to decode one by one
+			var elems []interface{}
+			for {
+				elem, err := decoder.Decode()
+				if err != nil {
+					break
+				}
+				elems = append(elems, elem)
+			}
+			if !reflect.DeepEqual(elems, tt.wantElems) {
+				t.Errorf("expected elements %#v, got %#v", tt.wantElems, elems)
+			}
+		})
+	}
+}
+
+func TestReadAggregateHeaderErrors(t *testing.T) {
+	// A reply of another type is consumed, leaving the next reply readable
+	reader := newReader("+OK\r\n*1\r\n:1\r\n")
+	if _, _, err := ReadArrayHeader(reader); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a simple string, got %v", err)
+	}
+	if count, _, err := ReadArrayHeader(reader); err != nil || count != 1 {
+		t.Errorf("expected (1, nil) after the skipped reply, got (%d, %v)", count, err)
+	}
+
+	var respErr *RespError
+	if _, _, err := ReadArrayHeader(newReader("-ERR boom\r\n")); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+
+	if _, _, err := ReadArrayHeader(newReader("*?\r\n:1\r\n.\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a streamed array, got %v", err)
+	}
+	if _, _, err := ReadMapHeader(newReader("%3\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for an odd map count, got %v", err)
+	}
+	if _, _, err := ReadSetHeader(newReader("*1\r\n:1\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for an array read as a set, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package resp3
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ServerInfo holds the server details returned in reply to a HELLO command.
+type ServerInfo struct {
+	Server  string // Server name, e.g. "redis"
+	Version string // Server version, e.g. "7.2.4"
+	Proto   int64  // Negotiated protocol version, 2 or 3
+	ID      int64  // Client connection id
+	Mode    string // "standalone", "sentinel" or "cluster"
+	Role    string // "master" or "replica"
+}
+
+// EncodeHello builds the HELLO command used to negotiate the protocol version
+// of a connection, as an array of bulk strings ready to be written to the server.
+// The AUTH section is omitted when both username and password are empty; a
+// password given without a username authenticates as the "default" user.
+//
+// Parameters:
+//   - version int: The protocol version to negotiate, 2 or 3.
+//   - username string: The user to authenticate as, or "" to use the default user.
+//   - password string: The password to authenticate with, or "" to skip authentication.
+//
+// Returns:
+//   - string: The encoded HELLO command.
+//   - error: An error if version is not a supported protocol version.
+//
+// Example usage:
+//
+//	cmd, err := EncodeHello(3, "alice", "secret")
+//	// cmd == "*5\r\n$5\r\nHELLO\r\n$1\r\n3\r\n$4\r\nAUTH\r\n$5\r\nalice\r\n$6\r\nsecret\r\n"
+func EncodeHello(version int, username, password string) (string, error) {
+	if version != 2 && version != 3 {
+		return "", fmt.Errorf("unsupported protocol version %d", version)
+	}
+
+	args := []string{"HELLO", strconv.Itoa(version)}
+	if username != "" || password != "" {
+		if username == "" {
+			username = "default"
+		}
+		args = append(args, "AUTH", username, password)
+	}
+	return EncodeCommand(args...), nil
+}
+
+// ParseHelloReply extracts the server details from a decoded HELLO reply. Both
+// the RESP3 map reply and the flat key/value array sent under RESP2 are accepted.
+//
+// Parameters:
+//   - v interface{}: The decoded reply to a HELLO command.
+//
+// Returns:
+//   - *ServerInfo: The server details found in the reply.
+//   - error: The server error if the reply is one, e.g. "-NOPROTO" or "-WRONGPASS",
+//     or an error wrapping ErrProtocol if the reply is not shaped like a HELLO reply.
+//
+// Example usage:
+//
+//	reply, _ := Decode(reader)
+//	info, err := ParseHelloReply(reply)
+func ParseHelloReply(v interface{}) (*ServerInfo, error) {
+	var respErr *RespError
+	if err, ok := v.(error); ok && errors.As(err, &respErr) {
+		return nil, respErr
+	}
+
+	fields, err := helloFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ServerInfo{}
+	for key, value := range fields {
+		switch key {
+		case "server":
+			info.Server, err = helloString(key, value)
+		case "version":
+			info.Version, err = helloString(key, value)
+		case "mode":
+			info.Mode, err = helloString(key, value)
+		case "role":
+			info.Role, err = helloString(key, value)
+		case "proto":
+			info.Proto, err = helloInt(key, value)
+		case "id":
+			info.ID, err = helloInt(key, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// helloFields returns the entries of a HELLO reply keyed by field name.
+func helloFields(v interface{}) (map[string]interface{}, error) {
+	switch reply := v.(type) {
+	case map[string]interface{}:
+		return reply, nil
+
+	case map[interface{}]interface{}:
+		fields := make(map[string]interface{}, len(reply))
+		for key, value := range reply {
+			if name, ok := stringValue(key); ok {
+				fields[name] = value
+			}
+		}
+		return fields, nil
+
+	case OrderedMap:
+		fields := make(map[string]interface{}, len(reply))
+		for _, kv := range reply {
+			if name, ok := stringValue(kv.Key); ok {
+				fields[name] = kv.Value
+			}
+		}
+		return fields, nil
+
+	case []interface{}:
+		if len(reply)%2 != 0 {
+			return nil, fmt.Errorf("HELLO reply has a field without a value: %w", ErrProtocol)
+		}
+		fields := make(map[string]interface{}, len(reply)/2)
+		for i := 0; i < len(reply); i += 2 {
+			if name, ok := stringValue(reply[i]); ok {
+				fields[name] = reply[i+1]
+			}
+		}
+		return fields, nil
+	}
+
+	return nil, fmt.Errorf("unexpected HELLO reply of type %T: %w", v, ErrProtocol)
+}
+
+// helloString returns a string field of a HELLO reply.
+func helloString(key string, value interface{}) (string, error) {
+	if s, ok := stringValue(value); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("HELLO reply field %q is %T, not a string: %w", key, value, ErrProtocol)
+}
+
+// helloInt returns an integer field of a HELLO reply.
+func helloInt(key string, value interface{}) (int64, error) {
+	switch i := value.(type) {
+	case int64:
+		return i, nil
+	case int:
+		return int64(i), nil // Decoded with NativeInt
+	}
+	return 0, fmt.Errorf("HELLO reply field %q is %T, not an integer: %w", key, value, ErrProtocol)
+}
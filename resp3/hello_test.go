@@ -0,0 +1,132 @@
+package resp3
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeHello(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		username string
+		password string
+		expected string
+	}{
+		{
+			name:     "Without credentials",
+			version:  3,
+			expected: "*2\r\n$5\r\nHELLO\r\n$1\r\n3\r\n",
+		},
+		{
+			name:     "With credentials",
+			version:  3,
+			username: "alice",
+			password: "secret",
+			expected: "*5\r\n$5\r\nHELLO\r\n$1\r\n3\r\n$4\r\nAUTH\r\n$5\r\nalice\r\n$6\r\nsecret\r\n",
+		},
+		{
+			name:     "Password only",
+			version:  2,
+			password: "secret",
+			expected: "*5\r\n$5\r\nHELLO\r\n$1\r\n2\r\n$4\r\nAUTH\r\n$7\r\ndefault\r\n$6\r\nsecret\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EncodeHello(tt.version, tt.username, tt.password)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := EncodeHello(4, "", ""); err == nil {
+		t.Errorf("expected an error for protocol version 4")
+	}
+}
+
+func TestParseHelloReply(t *testing.T) {
+	expected := &ServerInfo{Server: "redis", Version: "7.2.4", Proto: 3, ID: 10, Mode: "standalone", Role: "master"}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "RESP3 map",
+			input: "%14\r\n+server\r\n+redis\r\n+version\r\n+7.2.4\r\n+proto\r\n:3\r\n+id\r\n:10\r\n" +
+				"+mode\r\n+standalone\r\n+role\r\n+master\r\n+modules\r\n*0\r\n",
+		},
+		{
+			name: "RESP2 array",
+			input: "*14\r\n$6\r\nserver\r\n$5\r\nredis\r\n$7\r\nversion\r\n$5\r\n7.2.4\r\n$5\r\nproto\r\n:3\r\n$2\r\nid\r\n:10\r\n" +
+				"$4\r\nmode\r\n$10\r\nstandalone\r\n$4\r\nrole\r\n$6\r\nmaster\r\n$7\r\nmodules\r\n*0\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			info, err := ParseHelloReply(reply)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(info, expected) {
+				t.Errorf("expected %+v, got %+v", expected, info)
+			}
+		})
+	}
+}
+
+func TestParseHelloReplyDecoderOptions(t *testing.T) {
+	expected := &ServerInfo{Server: "redis", Version: "7.2.4", Proto: 2, ID: 10}
+	input := "*8\r\n$6\r\nserver\r\n$5\r\nredis\r\n$7\r\nversion\r\n$5\r\n7.2.4\r\n" +
+		"$5\r\nproto\r\n:2\r\n$2\r\nid\r\n:10\r\n"
+
+	decoder := NewDecoder(newReader(input))
+	decoder.NativeInt = true
+	decoder.RawBytes = true
+
+	reply, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	info, err := ParseHelloReply(reply)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+}
+
+func TestParseHelloReplyErrors(t *testing.T) {
+	reply, err := Decode(newReader("-NOPROTO unsupported protocol version\r\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var respErr *RespError
+	if _, err := ParseHelloReply(reply); !errors.As(err, &respErr) || respErr.Code != "NOPROTO" {
+		t.Errorf("expected NOPROTO error, got %v", err)
+	}
+
+	if _, err := ParseHelloReply("OK"); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol, got %v", err)
+	}
+
+	if _, err := ParseHelloReply(map[string]interface{}{"proto": "3"}); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol, got %v", err)
+	}
+}
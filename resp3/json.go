@@ -0,0 +1,233 @@
+package resp3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ToJSON converts a decoded RESP3 value into JSON. It normalizes the values that
+// json.Marshal cannot handle or would render unhelpfully:
+//
+//   - Maps with non-string keys, such as map[interface{}]interface{} and
+//     map[int64]interface{}, become objects keyed by the text of each key.
+//   - OrderedMap values become objects with their members in entry order.
+//   - Errors become {"error": "<message>"}.
+//   - Sets become arrays, with elements ordered as keys are in EncodeSorted.
+//   - PushMessage values become arrays.
+//   - []byte values become strings instead of base64 text.
+//   - VerbatimString values become their content.
+//   - Infinite and NaN doubles become the strings "inf", "-inf" and "nan".
+//   - *big.Int values become JSON numbers and *big.Float values strings.
+//
+// Parameters:
+//   - v interface{}: A value returned by Decode.
+//
+// Returns:
+//   - []byte: The JSON encoding of v.
+//   - error: An error if v holds a value that cannot be represented as JSON.
+//
+// Example usage:
+//
+//	value, _ := Decode(reader)
+//	data, err := ToJSON(value)
+func ToJSON(v interface{}) ([]byte, error) {
+	return json.Marshal(jsonValue(v))
+}
+
+// EncodeJSON converts a JSON document into its RESP3 encoding, routing the parsed
+// document through Encode. Objects become maps with their keys kept in document
+// order, arrays become arrays, true and false become booleans and null becomes
+// null. Numbers without a fraction or exponent that fit in an int64 (or uint64)
+// become integers, every other number becomes a double.
+//
+// Parameters:
+//   - data []byte: A single JSON document.
+//
+// Returns:
+//   - string: The RESP3 encoding of the document.
+//   - error: An error if data is not valid JSON or holds more than one document.
+//
+// Example usage:
+//
+//	encoded, err := EncodeJSON([]byte(`{"a":1,"b":[true,null]}`))
+//	// encoded == "%4\r\n+a\r\n:1\r\n+b\r\n*2\r\n#t\r\n_\r\n"
+func EncodeJSON(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := parseJSON(decoder)
+	if err != nil {
+		return "", err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", errors.New("unexpected data after JSON document")
+	}
+
+	return Encode(value)
+}
+
+// parseJSON reads the next JSON value from decoder, using OrderedMap for objects.
+func parseJSON(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			elements := []interface{}{}
+			for decoder.More() {
+				elem, err := parseJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, elem)
+			}
+			_, err := decoder.Token() // Closing ']'
+			return elements, err
+		}
+
+		object := OrderedMap{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			elem, err := parseJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, KeyValue{Key: key, Value: elem})
+		}
+		_, err := decoder.Token() // Closing '}'
+		return object, err
+	}
+
+	return token, nil // string, json.Number, bool or nil
+}
+
+// jsonValue returns v rewritten into types that json.Marshal renders as described on ToJSON.
+func jsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case []interface{}:
+		values := make([]interface{}, len(value))
+		for i, elem := range value {
+			values[i] = jsonValue(elem)
+		}
+		return values
+
+	case PushMessage:
+		return jsonValue([]interface{}(value))
+
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, elem := range value {
+			object[key] = jsonValue(elem)
+		}
+		return object
+
+	case OrderedMap:
+		object := make(jsonObject, len(value))
+		for i, kv := range value {
+			object[i] = KeyValue{Key: jsonKey(kv.Key), Value: jsonValue(kv.Value)}
+		}
+		return object
+
+	case Set:
+		elements := make([]interface{}, 0, len(value))
+		for elem := range value {
+			elements = append(elements, elem)
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			return compareKeys(elements[i], elements[j]) < 0
+		})
+		for i, elem := range elements {
+			elements[i] = jsonValue(elem)
+		}
+		return elements
+
+	case error:
+		return map[string]string{"error": value.Error()}
+
+	case []byte:
+		return string(value)
+
+	case VerbatimString:
+		return value.Content
+
+	case float64:
+		switch {
+		case math.IsInf(value, 1):
+			return "inf"
+		case math.IsInf(value, -1):
+			return "-inf"
+		case math.IsNaN(value):
+			return "nan"
+		}
+		return value
+	}
+
+	// Maps with keys of any other type, such as map[int64]interface{}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map {
+		object := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			object[jsonKey(iter.Key().Interface())] = jsonValue(iter.Value().Interface())
+		}
+		return object
+	}
+
+	return v
+}
+
+// jsonObject is a JSON object whose members are written in the order of its
+// entries, each keyed by a string, unlike a Go map, which json.Marshal sorts.
+type jsonObject []KeyValue
+
+// MarshalJSON implements json.Marshaler.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonKey returns the text used as the JSON object key for a map key.
+func jsonKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	case int64:
+		return strconv.FormatInt(k, 10)
+	}
+	return fmt.Sprint(key)
+}
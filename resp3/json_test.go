@@ -0,0 +1,171 @@
+package resp3
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Mixed-key map",
+			input:    "%4\r\n+a\r\n:1\r\n:2\r\n#t\r\n",
+			expected: `{"2":true,"a":1}`,
+		},
+		{
+			name:     "Integer-key map",
+			input:    "%2\r\n:1\r\n+one\r\n",
+			expected: `{"1":"one"}`,
+		},
+		{
+			name:     "Error",
+			input:    "*1\r\n-ERR unknown command\r\n",
+			expected: `[{"error":"ERR unknown command"}]`,
+		},
+		{
+			name:     "Set",
+			input:    "~3\r\n:2\r\n+b\r\n:1\r\n",
+			expected: `["b",1,2]`,
+		},
+		{
+			name:     "Verbatim string",
+			input:    "=6\r\ntxt:hi\r\n",
+			expected: `"hi"`,
+		},
+		{
+			name:     "Infinity",
+			input:    "*2\r\n,inf\r\n,-inf\r\n",
+			expected: `["inf","-inf"]`,
+		},
+		{
+			name:     "Null",
+			input:    "_\r\n",
+			expected: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			result, err := ToJSON(value)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestToJSONGoValues(t *testing.T) {
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	result, err := ToJSON(map[interface{}]interface{}{"n": n, "b": []byte("raw")})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `{"b":"raw","n":123456789012345678901234567890}`
+	if string(result) != expected {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Object keeps key order", input: `{"b":1,"a":2}`, expected: "%4\r\n+b\r\n:1\r\n+a\r\n:2\r\n"},
+		{name: "Array", input: `[true,false,null]`, expected: "*3\r\n#t\r\n#f\r\n_\r\n"},
+		{name: "Integer", input: `42`, expected: ":42\r\n"},
+		{name: "Large unsigned integer", input: `18446744073709551615`, expected: ":18446744073709551615\r\n"},
+		{name: "Float", input: `1.5`, expected: ",1.500000\r\n"},
+		{name: "Exponent", input: `1e3`, expected: ",1000.000000\r\n"},
+		{name: "String", input: `"hello"`, expected: "+hello\r\n"},
+		{name: "Empty object", input: `{}`, expected: "%0\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EncodeJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	for _, input := range []string{`{"a":`, `[1,]`, `1 2`, ``} {
+		if _, err := EncodeJSON([]byte(input)); err == nil {
+			t.Errorf("EncodeJSON(%q) expected an error", input)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	input := `{"list":[1,2.5,"three",true,null],"nested":{"k":"v"}}`
+
+	encoded, err := EncodeJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	value, err := Decode(newReader(encoded))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := ToJSON(value)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(result) != input {
+		t.Errorf("expected %s, got %s", input, result)
+	}
+}
+
+func TestToJSONOrderedMap(t *testing.T) {
+	input := `{"z":1,"a":{"y":true,"b":null},"m":[{"2":"x","1":"y"}]}`
+
+	encoded, err := EncodeJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	decoder := NewDecoder(newReader(encoded))
+	decoder.PreserveOrder = true
+	value, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := ToJSON(value)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(result) != input {
+		t.Errorf("expected %s, got %s", input, result)
+	}
+
+	result, err = ToJSON(OrderedMap{{Key: int64(2), Value: []byte("b")}, {Key: "a", Value: math.Inf(1)}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(result) != `{"2":"b","a":"inf"}` {
+		t.Errorf("expected %s, got %s", `{"2":"b","a":"inf"}`, result)
+	}
+}
@@ -0,0 +1,175 @@
+package resp3
+
+import (
+	"fmt"
+	"sync"
+)
+
+// maxPooledAggregateLen bounds the slices kept in aggregatePool, so a single huge
+// array does not pin its memory for the lifetime of the pool.
+const maxPooledAggregateLen = 4096
+
+// aggregatePool holds the backing slices of decoded arrays when the Decoder's
+// PoolAggregates option is enabled.
+var aggregatePool = sync.Pool{
+	New: func() interface{} {
+		elements := make([]interface{}, 0, 16)
+		return &elements
+	},
+}
+
+// getElements returns an empty slice with room for count elements, drawn from
+// aggregatePool when pooling is enabled.
+func (d *Decoder) getElements(count int) []interface{} {
+	if !d.PoolAggregates {
+		return make([]interface{}, 0, count)
+	}
+
+	elements := *aggregatePool.Get().(*[]interface{})
+	if cap(elements) < count {
+		return make([]interface{}, 0, count)
+	}
+	return elements[:0]
+}
+
+// putElements clears a slice and returns it to aggregatePool.
+func putElements(elements []interface{}) {
+	if cap(elements) > maxPooledAggregateLen {
+		return
+	}
+	clear(elements[:cap(elements)])
+	elements = elements[:0]
+	aggregatePool.Put(&elements)
+}
+
+// Release returns the arrays of a value decoded with PoolAggregates enabled to the
+// pool, including arrays nested inside arrays, pushes, sets decoded under
+// OrderedSets, maps, OrderedMap among them, and attributed values, so later
+// decodes can reuse them instead of allocating. The backing array of a push is
+// returned as well.
+//
+// Release transfers ownership back to the package: after calling it, v and every
+// array reachable from it may be overwritten by a later Decode at any time, so
+// none of them must be read, written or retained. Values that were not decoded
+// with PoolAggregates may be released too, their arrays simply join the pool.
+func Release(v interface{}) {
+	switch value := v.(type) {
+	case []interface{}:
+		for _, element := range value {
+			Release(element)
+		}
+		putElements(value)
+
+	case PushMessage:
+		for _, element := range value {
+			Release(element)
+		}
+		putElements(value)
+
+	case OrderedMap:
+		for _, entry := range value {
+			Release(entry.Key)
+			Release(entry.Value)
+		}
+
+	case Attributed:
+		for _, element := range value.Attributes {
+			Release(element)
+		}
+		Release(value.Value)
+
+	case map[string]interface{}:
+		for _, element := range value {
+			Release(element)
+		}
+
+	case map[int64]interface{}:
+		for _, element := range value {
+			Release(element)
+		}
+
+	case map[interface{}]interface{}:
+		for _, element := range value {
+			Release(element)
+		}
+	}
+}
+
+// DecodeReuse decodes the next reply, which must be an array, into the backing
+// array of prev instead of allocating a new []interface{}, for consumers that
+// process and discard replies in a tight loop. prev is truncated and refilled,
+// growing only if the reply holds more elements than fit; elements are decoded
+// as by Decode, except that the Values option does not apply.
+//
+// The returned slice shares its backing array with prev, so pass it back in on
+// the next call and do not retain it, or any slice of prev, once it has been
+// reused.
+//
+// Parameters:
+//   - prev []interface{}: The slice returned by the previous call, or nil.
+//
+// Returns:
+//   - []interface{}: The elements of the reply, or nil for a null array or the
+//     RESP3 null "_".
+//   - error: The decoded *RespError if the reply is an error reply, an error
+//     wrapping ErrProtocol if the reply is not an array, or a decoding error. A
+//     reply of another type is consumed entirely before returning.
+//
+// Example usage:
+//
+//	var elements []interface{}
+//	for {
+//	    elements, err = decoder.DecodeReuse(elements)
+//	    if err != nil {
+//	        break
+//	    }
+//	    // Process the elements
+//	}
+func (d *Decoder) DecodeReuse(prev []interface{}) ([]interface{}, error) {
+	start := d.offset
+	defer func() { d.lastFrameSize = int(d.offset - start) }()
+
+	dataType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if dataType != '*' {
+		// Consume the whole reply so the stream stays aligned
+		value, err := d.decodeFrame(dataType)
+		if err != nil {
+			return nil, d.frameError(dataType, err)
+		}
+		if respErr, ok := value.(*RespError); ok {
+			return nil, respErr
+		}
+		if dataType == '_' {
+			return nil, nil // RESP3 null, written for missing values like "*-1"
+		}
+		return nil, d.replyError(dataType, fmt.Errorf("expected an array reply, got %T: %w", value, ErrProtocol))
+	}
+
+	count, streamed, err := d.readCount()
+	if err != nil {
+		return nil, d.frameError(dataType, err)
+	}
+	if count == -1 {
+		return nil, nil // Null array
+	}
+
+	clear(prev) // Drop references to the previous elements
+	elements := prev[:0]
+	err = d.readElements(count, streamed, func() error {
+		element, err := d.decode()
+		if err != nil {
+			return err
+		}
+
+		elements = append(elements, element)
+		return nil
+	})
+	if err != nil {
+		return nil, d.frameError(dataType, err)
+	}
+	return elements, nil
+}
@@ -0,0 +1,194 @@
+package resp3
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoderPoolAggregates(t *testing.T) {
+	input := "*2\r\n*2\r\n:1\r\n:2\r\n%2\r\n+key\r\n*1\r\n+value\r\n*1\r\n:3\r\n"
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.PoolAggregates = true
+
+	first, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []interface{}{
+		[]interface{}{int64(1), int64(2)},
+		map[string]interface{}{"key": []interface{}{"value"}},
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("expected %v, got %v", expected, first)
+	}
+
+	Release(first)
+
+	second, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(second, []interface{}{int64(3)}) {
+		t.Errorf("expected [3], got %v", second)
+	}
+}
+
+func TestReleaseOrderedMap(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("%2\r\n+key\r\n*1\r\n+value\r\n"))
+	decoder.PoolAggregates = true
+	decoder.PreserveOrder = true
+
+	m, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	value := m.(OrderedMap)[0].Value.([]interface{})
+	Release(m)
+	if value[0] != nil {
+		t.Errorf("expected the array nested in the map to be cleared, got %v", value)
+	}
+}
+
+func TestReleasePushMessage(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader(">2\r\n+message\r\n*1\r\n:1\r\n"))
+	decoder.PoolAggregates = true
+
+	push, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	nested := push.(PushMessage)[1].([]interface{})
+	Release(push)
+	if push.(PushMessage)[0] != nil || nested[0] != nil {
+		t.Errorf("expected the push and its nested array to be cleared, got %v and %v", push, nested)
+	}
+}
+
+func TestDecoderDecodeReuse(t *testing.T) {
+	input := "*3\r\n:1\r\n+two\r\n#t\r\n*1\r\n:4\r\n*-1\r\n_\r\n*?\r\n:5\r\n:6\r\n.\r\n"
+	decoder := NewDecoder(strings.NewReader(input))
+
+	first, err := decoder.DecodeReuse(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(first, []interface{}{int64(1), "two", true}) {
+		t.Fatalf("expected [1 two true], got %v", first)
+	}
+
+	second, err := decoder.DecodeReuse(first)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(second, []interface{}{int64(4)}) {
+		t.Fatalf("expected [4], got %v", second)
+	}
+	if &second[0] != &first[0] {
+		t.Errorf("expected the backing array of prev to be reused")
+	}
+	if first[1] != nil || first[2] != nil {
+		t.Errorf("expected stale elements to be cleared, got %v", first[:3])
+	}
+
+	third, err := decoder.DecodeReuse(second)
+	if err != nil || third != nil {
+		t.Fatalf("expected (nil, nil) for a null array, got (%v, %v)", third, err)
+	}
+
+	if null, err := decoder.DecodeReuse(second); err != nil || null != nil {
+		t.Fatalf("expected (nil, nil) for the RESP3 null, got (%v, %v)", null, err)
+	}
+
+	fourth, err := decoder.DecodeReuse(second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(fourth, []interface{}{int64(5), int64(6)}) {
+		t.Errorf("expected [5 6], got %v", fourth)
+	}
+}
+
+func TestDecoderDecodeReuseErrors(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("+OK\r\n-ERR boom\r\n*1\r\n:1\r\n"))
+
+	if _, err := decoder.DecodeReuse(nil); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a simple string, got %v", err)
+	}
+
+	var respErr *RespError
+	if _, err := decoder.DecodeReuse(nil); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+
+	if elements, err := decoder.DecodeReuse(nil); err != nil || len(elements) != 1 {
+		t.Errorf("expected one element after the rejected replies, got (%v, %v)", elements, err)
+	}
+}
+
+func benchmarkDecodeArrays(b *testing.B, pooled bool) {
+	input := "*4\r\n" + strings.Repeat("*64\r\n"+strings.Repeat("#t\r\n", 64), 4)
+	source := strings.NewReader(input)
+	reader := bufio.NewReader(source)
+	decoder := NewDecoder(reader)
+	decoder.PoolAggregates = pooled
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		source.Reset(input)
+		reader.Reset(source)
+
+		value, err := decoder.Decode()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if pooled {
+			Release(value)
+		}
+	}
+}
+
+func BenchmarkDecodeArrays(b *testing.B) {
+	benchmarkDecodeArrays(b, false)
+}
+
+func BenchmarkDecodeArraysPooled(b *testing.B) {
+	benchmarkDecodeArrays(b, true)
+}
+
+func benchmarkDecodeFlatArray(b *testing.B, reuse bool) {
+	input := "*256\r\n" + strings.Repeat("#t\r\n", 256)
+	source := strings.NewReader(input)
+	reader := bufio.NewReader(source)
+	decoder := NewDecoder(reader)
+	var elements []interface{}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		source.Reset(input)
+		reader.Reset(source)
+
+		var err error
+		if reuse {
+			elements, err = decoder.DecodeReuse(elements)
+		} else {
+			_, err = decoder.Decode()
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeFlatArray(b *testing.B) {
+	benchmarkDecodeFlatArray(b, false)
+}
+
+func BenchmarkDecodeFlatArrayReuse(b *testing.B) {
+	benchmarkDecodeFlatArray(b, true)
+}
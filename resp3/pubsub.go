@@ -0,0 +1,124 @@
+package resp3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PubSubEvent is a pub/sub or client-side caching push parsed by ParsePubSub.
+// Only the fields that apply to its Kind are set:
+//
+//   - "message", "smessage": Channel and Payload.
+//   - "pmessage": Pattern, Channel and Payload.
+//   - "subscribe", "psubscribe", "ssubscribe", "unsubscribe", "punsubscribe",
+//     "sunsubscribe": Channel, the channel or pattern concerned, and Count, the
+//     number of subscriptions the connection holds afterwards.
+//   - "invalidate": Keys, the invalidated keys, or nil when the server flushed
+//     every key, as after FLUSHALL.
+type PubSubEvent struct {
+	Kind    string
+	Channel string
+	Pattern string
+	Payload string
+	Count   int64
+	Keys    []string
+}
+
+// ParsePubSub parses a push message such as a pub/sub message or a client-side
+// caching invalidation into a PubSubEvent, so a subscriber loop can switch on
+// its Kind rather than pick apart the raw elements.
+//
+// Parameters:
+//   - p PushMessage: A push returned by Decode.
+//
+// Returns:
+//   - *PubSubEvent: The parsed event.
+//   - error: An error wrapping ErrProtocol if the push is of an unrecognized kind
+//     or does not have the shape of its kind.
+//
+// Example usage:
+//
+//	event, err := ParsePubSub(push) // ">3\r\n+message\r\n+news\r\n+hello\r\n"
+//	// event.Kind == "message", event.Channel == "news", event.Payload == "hello"
+func ParsePubSub(p PushMessage) (*PubSubEvent, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty push message: %w", ErrProtocol)
+	}
+
+	kind, ok := stringValue(p[0])
+	if !ok {
+		return nil, fmt.Errorf("push kind is %T, not a string: %w", p[0], ErrProtocol)
+	}
+	event := &PubSubEvent{Kind: strings.ToLower(kind)}
+
+	switch event.Kind {
+	case "message", "smessage":
+		if err := parsePushStrings(p, &event.Channel, &event.Payload); err != nil {
+			return nil, err
+		}
+
+	case "pmessage":
+		if err := parsePushStrings(p, &event.Pattern, &event.Channel, &event.Payload); err != nil {
+			return nil, err
+		}
+
+	case "subscribe", "psubscribe", "ssubscribe", "unsubscribe", "punsubscribe", "sunsubscribe":
+		if len(p) != 3 {
+			return nil, fmt.Errorf("%s push has %d elements, want 3: %w", event.Kind, len(p), ErrProtocol)
+		}
+		// Unsubscribing while holding no subscription reports a null channel
+		if p[1] != nil {
+			channel, ok := stringValue(p[1])
+			if !ok {
+				return nil, fmt.Errorf("%s push channel is %T, not a string: %w", event.Kind, p[1], ErrProtocol)
+			}
+			event.Channel = channel
+		}
+		switch count := p[2].(type) {
+		case int64:
+			event.Count = count
+		case int:
+			event.Count = int64(count) // Decoded with NativeInt
+		default:
+			return nil, fmt.Errorf("%s push count is %T, not an integer: %w", event.Kind, p[2], ErrProtocol)
+		}
+
+	case "invalidate":
+		if len(p) != 2 {
+			return nil, fmt.Errorf("invalidate push has %d elements, want 2: %w", len(p), ErrProtocol)
+		}
+		if p[1] == nil {
+			return event, nil // Every key was flushed
+		}
+		keys, ok := p[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalidate push keys are %T, not an array: %w", p[1], ErrProtocol)
+		}
+		event.Keys = make([]string, len(keys))
+		for i, key := range keys {
+			if event.Keys[i], ok = stringValue(key); !ok {
+				return nil, fmt.Errorf("invalidate push key %d is %T, not a string: %w", i, key, ErrProtocol)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unrecognized push kind %q: %w", kind, ErrProtocol)
+	}
+	return event, nil
+}
+
+// parsePushStrings stores the string elements following the kind of p in dst,
+// requiring p to hold exactly that many elements.
+func parsePushStrings(p PushMessage, dst ...*string) error {
+	if len(p) != len(dst)+1 {
+		return fmt.Errorf("%v push has %d elements, want %d: %w", p[0], len(p), len(dst)+1, ErrProtocol)
+	}
+	for i, d := range dst {
+		s, ok := stringValue(p[i+1])
+		if !ok {
+			return fmt.Errorf("%v push element %d is %T, not a string: %w", p[0], i+1, p[i+1], ErrProtocol)
+		}
+		*d = s
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package resp3
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePubSub(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *PubSubEvent
+	}{
+		{
+			name:     "Message",
+			input:    ">3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n",
+			expected: &PubSubEvent{Kind: "message", Channel: "news", Payload: "hello"},
+		},
+		{
+			name:     "Pattern message",
+			input:    ">4\r\n$8\r\npmessage\r\n$2\r\nn*\r\n$4\r\nnews\r\n$5\r\nhello\r\n",
+			expected: &PubSubEvent{Kind: "pmessage", Pattern: "n*", Channel: "news", Payload: "hello"},
+		},
+		{
+			name:     "Subscribe",
+			input:    ">3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n",
+			expected: &PubSubEvent{Kind: "subscribe", Channel: "news", Count: 1},
+		},
+		{
+			name:     "Unsubscribe without subscriptions",
+			input:    ">3\r\n$11\r\nunsubscribe\r\n_\r\n:0\r\n",
+			expected: &PubSubEvent{Kind: "unsubscribe", Count: 0},
+		},
+		{
+			name:     "Invalidate keys",
+			input:    ">2\r\n$10\r\ninvalidate\r\n*2\r\n$1\r\na\r\n$1\r\nb\r\n",
+			expected: &PubSubEvent{Kind: "invalidate", Keys: []string{"a", "b"}},
+		},
+		{
+			name:     "Invalidate all",
+			input:    ">2\r\n$10\r\ninvalidate\r\n_\r\n",
+			expected: &PubSubEvent{Kind: "invalidate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			event, err := ParsePubSub(decoded.(PushMessage))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(event, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, event)
+			}
+		})
+	}
+}
+
+func TestParsePubSubInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input PushMessage
+	}{
+		{"Empty", PushMessage{}},
+		{"Unrecognized kind", PushMessage{"tracking-redir-broken"}},
+		{"Kind not a string", PushMessage{int64(1), "news", "hello"}},
+		{"Message missing payload", PushMessage{"message", "news"}},
+		{"Subscribe count not an integer", PushMessage{"subscribe", "news", "1"}},
+		{"Invalidate keys not an array", PushMessage{"invalidate", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePubSub(tt.input); !errors.Is(err, ErrProtocol) {
+				t.Errorf("expected ErrProtocol, got %v", err)
+			}
+		})
+	}
+}
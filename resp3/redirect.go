@@ -0,0 +1,54 @@
+package resp3
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Redirect describes a cluster redirection carried by a MOVED or ASK error,
+// e.g. "-MOVED 3999 127.0.0.1:6381".
+type Redirect struct {
+	Kind string // "MOVED" or "ASK"
+	Slot int
+	Addr string // host:port of the node owning the slot
+}
+
+// ParseRedirect extracts the redirection details from a MOVED or ASK error.
+// It is a pure parsing helper over the *RespError values returned by Decode;
+// the decoder itself keeps returning such errors unchanged.
+//
+// Parameters:
+//   - err error: The error to inspect, typically a decoded *RespError.
+//
+// Returns:
+//   - *Redirect: The parsed redirection, or nil if err is not a redirect.
+//   - bool: true if err is a well-formed MOVED or ASK error.
+//
+// Example usage:
+//
+//	if redirect, ok := ParseRedirect(err); ok {
+//	    // retry against redirect.Addr
+//	}
+func ParseRedirect(err error) (*Redirect, bool) {
+	var respErr *RespError
+	if !errors.As(err, &respErr) {
+		return nil, false
+	}
+
+	if respErr.Code != "MOVED" && respErr.Code != "ASK" {
+		return nil, false
+	}
+
+	fields := strings.Fields(respErr.Message)
+	if len(fields) != 2 {
+		return nil, false
+	}
+
+	slot, convErr := strconv.Atoi(fields[0])
+	if convErr != nil {
+		return nil, false
+	}
+
+	return &Redirect{Kind: respErr.Code, Slot: slot, Addr: fields[1]}, true
+}
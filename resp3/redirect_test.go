@@ -0,0 +1,59 @@
+package resp3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Redirect
+	}{
+		{
+			name:     "Moved",
+			input:    "-MOVED 3999 127.0.0.1:6381\r\n",
+			expected: &Redirect{Kind: "MOVED", Slot: 3999, Addr: "127.0.0.1:6381"},
+		},
+		{
+			name:     "Ask",
+			input:    "-ASK 3999 127.0.0.1:6381\r\n",
+			expected: &Redirect{Kind: "ASK", Slot: 3999, Addr: "127.0.0.1:6381"},
+		},
+		{
+			name:     "Not a redirect",
+			input:    "-ERR unknown command\r\n",
+			expected: nil,
+		},
+		{
+			name:     "Malformed slot",
+			input:    "-MOVED abc 127.0.0.1:6381\r\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			redirect, ok := ParseRedirect(result.(error))
+			if ok != (tt.expected != nil) {
+				t.Fatalf("expected ok=%v, got %v", tt.expected != nil, ok)
+			}
+
+			if ok && *redirect != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, redirect)
+			}
+		})
+	}
+}
+
+func TestParseRedirectPlainError(t *testing.T) {
+	if _, ok := ParseRedirect(errors.New("MOVED 3999 127.0.0.1:6381")); ok {
+		t.Errorf("expected plain errors not to be parsed as redirects")
+	}
+}
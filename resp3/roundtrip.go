@@ -0,0 +1,55 @@
+package resp3
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+)
+
+// RoundTrip encodes v with Encode, decodes the result with Decode and converts the
+// decoded value back to the type of v where a conversion exists, so that
+// reflect.DeepEqual(v, result) reports whether v survives the trip through RESP3.
+// When no conversion exists the decoded value is returned as is, which makes the
+// mismatch visible to such a comparison.
+//
+// Values round-trip losslessly when they are built from strings, []byte, booleans,
+// integers that fit in an int64, floats, VerbatimString, SimpleString, time.Duration
+// and nil, including slices, maps, pointers and structs of them (structs come
+// back field by field, unexported fields are not encoded). The following do not:
+//
+//   - time.Time values are encoded as a timestamp and come back as int64.
+//   - Errors come back as *RespError carrying only their message.
+//   - Fixed-size arrays come back as slices, and sql.Null* values as their underlying value.
+//
+// Parameters:
+//   - v interface{}: The value to send through the encoder and decoder.
+//
+// Returns:
+//   - interface{}: The decoded value, converted to the type of v where possible.
+//   - error: An error if v cannot be encoded or its encoding cannot be decoded.
+//
+// Example usage:
+//
+//	result, err := RoundTrip([]int{1, 2, 3})
+//	// result == []int{1, 2, 3}, where a plain Decode returns []interface{}{int64(1), ...}
+func RoundTrip(v interface{}) (interface{}, error) {
+	encoded, err := Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := Decode(bufio.NewReader(strings.NewReader(encoded)))
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return decoded, nil
+	}
+
+	result := reflect.New(reflect.TypeOf(v)).Elem()
+	if err := assignValue(result, decoded); err != nil {
+		return decoded, nil // Not convertible, let the caller see the decoded form
+	}
+	return result.Interface(), nil
+}
@@ -0,0 +1,76 @@
+package resp3
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoundTripLossless(t *testing.T) {
+	value := "v"
+
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{name: "Int", input: 42},
+		{name: "Int8", input: int8(-8)},
+		{name: "Uint32", input: uint32(7)},
+		{name: "Float64", input: 0.1234567891234},
+		{name: "Tiny float", input: 1e-9},
+		{name: "Float32", input: float32(1.1)},
+		{name: "Infinity", input: math.Inf(-1)},
+		{name: "Bool", input: true},
+		{name: "Short string", input: "hello"},
+		{name: "Long string", input: "a string longer than sixteen bytes"},
+		{name: "Bytes", input: []byte("raw")},
+		{name: "String slice", input: []string{"a", "b"}},
+		{name: "Nested slices", input: [][]int{{1, 2}, {3}}},
+		{name: "Nil slice", input: []int(nil)},
+		{name: "Map", input: map[string]int{"a": 1, "b": 2}},
+		{name: "Integer-key map", input: map[int]string{1: "one"}},
+		{name: "Pointer", input: &value},
+		{name: "Duration", input: 1500 * time.Millisecond},
+		{name: "Verbatim string", input: VerbatimString{Format: "txt", Content: "hi"}},
+		{name: "Struct", input: ScalarRecord{Value: "v", Type: 1, LAT: 2, Expiry: 3}},
+		{name: "Push", input: PushMessage{"message", "news", int64(1)}},
+		{name: "Attributed", input: Attributed{Attributes: map[string]interface{}{"ttl": int64(60)}, Value: "v"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RoundTrip(tt.input)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.input) {
+				t.Errorf("expected %#v, got %#v", tt.input, result)
+			}
+		})
+	}
+}
+
+func TestRoundTripLossy(t *testing.T) {
+	result, err := RoundTrip(errors.New("ERR boom"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result, &RespError{Code: "ERR", Message: "boom"}) {
+		t.Errorf("expected *RespError, got %#v", result)
+	}
+
+	ts := time.UnixMilli(1620832335000)
+	result, err = RoundTrip(ts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != int64(1620832335000) {
+		t.Errorf("expected the millisecond timestamp, got %#v", result)
+	}
+
+	if _, err := RoundTrip(make(chan int)); err == nil {
+		t.Errorf("expected an error for an unsupported type")
+	}
+}
@@ -0,0 +1,282 @@
+package resp3
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Scan decodes an array reply from reader and assigns its elements, in order, to
+// the values pointed to by dest, in the spirit of database/sql's Rows.Scan.
+// Elements are converted to the destination type where a lossless conversion
+// exists: integers, doubles, booleans and strings convert between each other
+// (e.g. "42" into an *int, 1 into a *bool), strings and []byte are
+// interchangeable, arrays and maps convert into slices, maps and structs of
+// convertible types, and a null element stores the zero value, or nil for pointer
+// destinations. A *interface{} destination receives the element as decoded.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of an array reply.
+//   - dest ...interface{}: One non-nil pointer per array element.
+//
+// Returns:
+//   - error: nil on success; the decoded error if the reply is an error reply; an
+//     error wrapping ErrProtocol if the reply is not an array, its length differs
+//     from len(dest), or an element cannot be converted to its destination's type;
+//     or an error if decoding fails.
+//
+// Example usage:
+//
+//	var name string
+//	var age int
+//	var active bool
+//	err := Scan(reader, &name, &age, &active)
+func Scan(reader *bufio.Reader, dest ...interface{}) error {
+	value, err := Decode(reader)
+	if err != nil {
+		return err
+	}
+
+	if respErr, ok := value.(*RespError); ok {
+		return respErr
+	}
+
+	elements, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("scan: expected an array reply, got %T: %w", value, ErrProtocol)
+	}
+	if len(elements) != len(dest) {
+		return fmt.Errorf("scan: reply has %d elements but %d destinations were given: %w", len(elements), len(dest), ErrProtocol)
+	}
+
+	for i, elem := range elements {
+		if err := scanValue(dest[i], elem); err != nil {
+			return fmt.Errorf("scan index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// scanValue stores src into the value pointed to by dest.
+func scanValue(dest, src interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	return assignValue(rv.Elem(), src)
+}
+
+// assignValue stores src into dst, converting it to the type of dst.
+func assignValue(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	if verbatim, ok := src.(VerbatimString); ok {
+		src = verbatim.Content
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+			return nil
+		case []byte:
+			dst.SetString(string(s))
+			return nil
+		case int64:
+			dst.SetString(strconv.FormatInt(s, 10))
+			return nil
+		case float64:
+			dst.SetString(strconv.FormatFloat(s, 'f', -1, 64))
+			return nil
+		case bool:
+			dst.SetString(strconv.FormatBool(s))
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := scanInt(src)
+		if err != nil {
+			return err
+		}
+		if i == nil {
+			break
+		}
+		if dst.OverflowInt(*i) {
+			return fmt.Errorf("value %d overflows %s: %w", *i, dst.Type(), ErrProtocol)
+		}
+		dst.SetInt(*i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, ok := src.(uint64); ok { // Integers above math.MaxInt64
+			if dst.OverflowUint(u) {
+				return fmt.Errorf("value %d overflows %s: %w", u, dst.Type(), ErrProtocol)
+			}
+			dst.SetUint(u)
+			return nil
+		}
+
+		i, err := scanInt(src)
+		if err != nil {
+			return err
+		}
+		if i == nil {
+			break
+		}
+		if *i < 0 || dst.OverflowUint(uint64(*i)) {
+			return fmt.Errorf("value %d overflows %s: %w", *i, dst.Type(), ErrProtocol)
+		}
+		dst.SetUint(uint64(*i))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch s := src.(type) {
+		case float64:
+			f = s
+		case int64:
+			f = float64(s)
+		case string, []byte:
+			parsed, err := strconv.ParseFloat(scanString(s), 64)
+			if err != nil {
+				return fmt.Errorf("invalid double %q: %w: %w", scanString(s), ErrProtocol, err)
+			}
+			f = parsed
+		default:
+			return cannotAssign(src, dst)
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Bool:
+		switch s := src.(type) {
+		case bool:
+			dst.SetBool(s)
+			return nil
+		case int64:
+			dst.SetBool(s != 0)
+			return nil
+		case string, []byte:
+			b, err := strconv.ParseBool(scanString(s))
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q: %w: %w", scanString(s), ErrProtocol, err)
+			}
+			dst.SetBool(b)
+			return nil
+		}
+
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch s := src.(type) {
+			case string:
+				dst.SetBytes([]byte(s))
+				return nil
+			case []byte:
+				dst.SetBytes(append([]byte(nil), s...))
+				return nil
+			}
+		}
+
+		if elements, ok := src.([]interface{}); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(elements), len(elements))
+			for i, elem := range elements {
+				if err := assignValue(slice.Index(i), elem); err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+
+	case reflect.Map:
+		if sv.Kind() == reflect.Map {
+			m := reflect.MakeMapWithSize(dst.Type(), sv.Len())
+			iter := sv.MapRange()
+			for iter.Next() {
+				key := reflect.New(dst.Type().Key()).Elem()
+				if err := assignValue(key, iter.Key().Interface()); err != nil {
+					return err
+				}
+				value := reflect.New(dst.Type().Elem()).Elem()
+				if err := assignValue(value, iter.Value().Interface()); err != nil {
+					return err
+				}
+				m.SetMapIndex(key, value)
+			}
+			dst.Set(m)
+			return nil
+		}
+
+	case reflect.Struct:
+		// Structs are encoded as maps of their exported field names
+		if fields, ok := src.(map[string]interface{}); ok {
+			for i := 0; i < dst.NumField(); i++ {
+				field := dst.Type().Field(i)
+				value, found := fields[field.Name]
+				if !field.IsExported() || !found {
+					continue
+				}
+				if err := assignValue(dst.Field(i), value); err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+			}
+			return nil
+		}
+	}
+
+	return cannotAssign(src, dst)
+}
+
+// scanInt converts src to an integer, returning nil if src has no integer form.
+func scanInt(src interface{}) (*int64, error) {
+	switch s := src.(type) {
+	case int64:
+		return &s, nil
+	case bool:
+		var i int64
+		if s {
+			i = 1
+		}
+		return &i, nil
+	case string, []byte:
+		i, err := strconv.ParseInt(scanString(s), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q: %w: %w", scanString(s), ErrProtocol, err)
+		}
+		return &i, nil
+	}
+	return nil, nil
+}
+
+// scanString returns the text of a string or []byte value.
+func scanString(src interface{}) string {
+	if b, ok := src.([]byte); ok {
+		return string(b)
+	}
+	return src.(string)
+}
+
+// cannotAssign reports that src has no conversion to the type of dst.
+func cannotAssign(src interface{}, dst reflect.Value) error {
+	return fmt.Errorf("cannot assign %T to %s: %w", src, dst.Type(), ErrProtocol)
+}
@@ -0,0 +1,80 @@
+package resp3
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	var (
+		name    string
+		age     int
+		active  bool
+		score   float64
+		count   uint8
+		raw     []byte
+		missing *string
+		label   *string
+		any     interface{}
+	)
+
+	input := "*9\r\n+alice\r\n$2\r\n42\r\n:1\r\n:7\r\n+200\r\n$3\r\nraw\r\n_\r\n+x\r\n%2\r\n+k\r\n+v\r\n"
+	err := Scan(newReader(input), &name, &age, &active, &score, &count, &raw, &missing, &label, &any)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if name != "alice" || age != 42 || !active || score != 7 || count != 200 || string(raw) != "raw" {
+		t.Errorf("unexpected values: %q %d %t %v %d %q", name, age, active, score, count, raw)
+	}
+	if missing != nil {
+		t.Errorf("expected nil pointer for null element, got %q", *missing)
+	}
+	if label == nil || *label != "x" {
+		t.Errorf("expected pointer to %q, got %v", "x", label)
+	}
+	if !reflect.DeepEqual(any, map[string]interface{}{"k": "v"}) {
+		t.Errorf("expected decoded map, got %v", any)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	var s string
+	var i int
+	var u uint8
+
+	tests := []struct {
+		name     string
+		input    string
+		dest     []interface{}
+		contains string
+		protocol bool
+	}{
+		{name: "Count mismatch", input: "*2\r\n+a\r\n+b\r\n", dest: []interface{}{&s}, contains: "2 elements but 1 destinations", protocol: true},
+		{name: "Not an array", input: "+OK\r\n", dest: []interface{}{&s}, contains: "expected an array reply", protocol: true},
+		{name: "Not a pointer", input: "*1\r\n+a\r\n", dest: []interface{}{s}, contains: "non-nil pointer"},
+		{name: "Unparsable integer", input: "*1\r\n+abc\r\n", dest: []interface{}{&i}, contains: "scan index 0", protocol: true},
+		{name: "Overflow", input: "*2\r\n+a\r\n:300\r\n", dest: []interface{}{&s, &u}, contains: "scan index 1: value 300 overflows uint8", protocol: true},
+		{name: "Unsigned overflow", input: "*1\r\n:18446744073709551615\r\n", dest: []interface{}{&u}, contains: "value 18446744073709551615 overflows uint8", protocol: true},
+		{name: "Incompatible type", input: "*1\r\n*0\r\n", dest: []interface{}{&i}, contains: "cannot assign []interface {} to int", protocol: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Scan(newReader(tt.input), tt.dest...)
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+			if errors.Is(err, ErrProtocol) != tt.protocol {
+				t.Errorf("expected errors.Is(err, ErrProtocol) to be %t, got %v", tt.protocol, err)
+			}
+		})
+	}
+
+	var respErr *RespError
+	if err := Scan(newReader("-ERR boom\r\n"), &s); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package resp3
+
+import "io"
+
+// Scanner reads consecutive replies from a stream, such as the messages of a
+// pub/sub subscription, in the style of bufio.Scanner. Each call to Scan decodes
+// one reply, which Value then returns. Pushes are returned as PushMessage values
+// and error replies as *RespError values, like any other reply.
+//
+// Example usage:
+//
+//	scanner := NewScanner(conn)
+//	for scanner.Scan() {
+//	    if push, ok := scanner.Value().(PushMessage); ok {
+//	        // Handle the pub/sub message
+//	    }
+//	}
+//	if err := scanner.Err(); err != nil {
+//	    // The connection failed or sent malformed data
+//	}
+type Scanner struct {
+	decoder *Decoder
+	value   interface{}
+	err     error
+}
+
+// NewScanner returns a Scanner that reads replies from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{decoder: NewDecoder(r)}
+}
+
+// Decoder returns the Decoder the Scanner reads through, whose options may be set
+// before the first call to Scan.
+func (s *Scanner) Decoder() *Decoder {
+	return s.decoder
+}
+
+// Scan decodes the next reply, blocking until it has arrived, and reports whether
+// one was decoded. It returns false once the stream ends or decoding fails, after
+// which Err reports the reason.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	s.value, s.err = s.decoder.Decode()
+	if s.err != nil {
+		s.value = nil
+		return false
+	}
+	return true
+}
+
+// Value returns the reply decoded by the most recent successful call to Scan.
+func (s *Scanner) Value() interface{} {
+	return s.value
+}
+
+// Err returns the error that stopped the Scanner: nil if the stream ended cleanly
+// between two replies, io.ErrUnexpectedEOF if it ended in the middle of one, and
+// the decoding or read error otherwise.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
@@ -0,0 +1,71 @@
+package resp3
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanner(t *testing.T) {
+	input := "*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n" +
+		">3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n" +
+		"-ERR boom\r\n" +
+		"+PONG\r\n"
+
+	expected := []interface{}{
+		[]interface{}{"subscribe", "news", int64(1)},
+		PushMessage{"message", "news", "hello"},
+		&RespError{Code: "ERR", Message: "boom"},
+		"PONG",
+	}
+
+	// Deliver the stream a byte at a time, as a slow connection would
+	scanner := NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+
+	var values []interface{}
+	for scanner.Scan() {
+		values = append(values, scanner.Value())
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("expected no error at a clean end of stream, got %v", err)
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %#v, got %#v", expected, values)
+	}
+	if scanner.Scan() || scanner.Value() != nil {
+		t.Errorf("expected no more values after the end of the stream")
+	}
+}
+
+func TestScannerErrors(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("+first\r\n*2\r\n:1\r\n"))
+	if !scanner.Scan() || scanner.Value() != "first" {
+		t.Fatalf("expected the first reply, got %v (err %v)", scanner.Value(), scanner.Err())
+	}
+	if scanner.Scan() {
+		t.Fatalf("expected Scan to stop at a truncated reply, got %v", scanner.Value())
+	}
+	if err := scanner.Err(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	scanner = NewScanner(strings.NewReader(":1\r\n:x\r\n:2\r\n"))
+	for scanner.Scan() {
+	}
+	if err := scanner.Err(); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol, got %v", err)
+	}
+}
+
+func TestScannerDecoderOptions(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("+OK\r\n"))
+	scanner.Decoder().SimpleStrings = true
+
+	if !scanner.Scan() || scanner.Value() != SimpleString("OK") {
+		t.Errorf("expected SimpleString(\"OK\"), got %#v (err %v)", scanner.Value(), scanner.Err())
+	}
+}
@@ -0,0 +1,119 @@
+package resp3
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Key ranks used to order map keys of different types relative to each other.
+const (
+	rankString = iota
+	rankInteger
+	rankFloat
+	rankBool
+	rankOther
+)
+
+// mapEntry is a single key/value pair of a map being encoded.
+type mapEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// sortedEntries collects the pairs produced by entries and returns an equivalent
+// iterator yielding them ordered by compareKeys.
+func sortedEntries(size int, entries func(entry func(key, value interface{}) error) error) func(entry func(key, value interface{}) error) error {
+	return func(entry func(key, value interface{}) error) error {
+		pairs := make([]mapEntry, 0, size)
+		err := entries(func(key, value interface{}) error {
+			pairs = append(pairs, mapEntry{key: key, value: value})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return compareKeys(pairs[i].key, pairs[j].key) < 0
+		})
+
+		for _, pair := range pairs {
+			if err := entry(pair.key, pair.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// compareKeys defines the total order used for deterministic map encoding:
+// strings first (lexicographically), then integers of any width (numerically),
+// then floats (numerically), then booleans (false before true), and finally any
+// other key type ordered by its Go syntax representation.
+func compareKeys(a, b interface{}) int {
+	rankA, rankB := keyRank(a), keyRank(b)
+	if rankA != rankB {
+		return cmp.Compare(rankA, rankB)
+	}
+
+	valA, valB := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch rankA {
+	case rankString:
+		return strings.Compare(valA.String(), valB.String())
+	case rankInteger:
+		return compareIntegers(valA, valB)
+	case rankFloat:
+		return cmp.Compare(valA.Float(), valB.Float())
+	case rankBool:
+		if valA.Bool() == valB.Bool() {
+			return 0
+		}
+		if valB.Bool() {
+			return -1
+		}
+		return 1
+	default:
+		return strings.Compare(fmt.Sprintf("%#v", a), fmt.Sprintf("%#v", b))
+	}
+}
+
+// keyRank classifies a map key for compareKeys.
+func keyRank(key interface{}) int {
+	if key == nil {
+		return rankOther
+	}
+
+	switch reflect.TypeOf(key).Kind() {
+	case reflect.String:
+		return rankString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rankInteger
+	case reflect.Float32, reflect.Float64:
+		return rankFloat
+	case reflect.Bool:
+		return rankBool
+	default:
+		return rankOther
+	}
+}
+
+// compareIntegers numerically compares two signed or unsigned integer values.
+func compareIntegers(a, b reflect.Value) int {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanInt(): // b is unsigned
+		if a.Int() < 0 {
+			return -1
+		}
+		return cmp.Compare(uint64(a.Int()), b.Uint())
+	default: // a is unsigned, b is signed
+		return -compareIntegers(b, a)
+	}
+}
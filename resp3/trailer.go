@@ -0,0 +1,51 @@
+package resp3
+
+import (
+	"bufio"
+	"io"
+)
+
+// DecodeWithTrailer decodes a value followed by a trailer frame and validates the
+// pair. It supports deployments that append a non-standard integrity frame, such
+// as a checksum, after each reply. The trailer is an ordinary RESP3 frame, so it
+// is decoded with the same rules as the value.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the value.
+//   - validate func(value, trailer interface{}) error: Called with the decoded value
+//     and trailer; a non-nil result is returned as the error.
+//
+// Returns:
+//   - interface{}: The decoded value, or nil if decoding or validation failed.
+//   - error: A decode error, io.ErrUnexpectedEOF if the trailer is missing, or the
+//     error returned by validate.
+//
+// Example usage:
+//
+//	value, err := DecodeWithTrailer(reader, func(value, trailer interface{}) error {
+//	    if trailer != checksum(value) {
+//	        return errors.New("checksum mismatch")
+//	    }
+//	    return nil
+//	})
+func DecodeWithTrailer(reader *bufio.Reader, validate func(value, trailer interface{}) error) (interface{}, error) {
+	decoder := &Decoder{reader: reader}
+
+	value, err := decoder.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	trailer, err := decoder.Decode()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validate(value, trailer); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
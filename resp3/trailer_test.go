@@ -0,0 +1,81 @@
+package resp3
+
+import (
+	"errors"
+	"hash/crc32"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func checksumValidator(value, trailer interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("expected string value")
+	}
+	if trailer != int64(crc32.ChecksumIEEE([]byte(str))) {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+func TestDecodeWithTrailer(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  interface{}
+		expectErr bool
+	}{
+		{
+			name:     "Valid checksum",
+			input:    "$11\r\nhello world\r\n:222957957\r\n",
+			expected: "hello world",
+		},
+		{
+			name:      "Invalid checksum",
+			input:     "$11\r\nhello world\r\n:1\r\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeWithTrailer(newReader(tt.input), checksumValidator)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeWithTrailerMissingTrailer(t *testing.T) {
+	_, err := DecodeWithTrailer(newReader("$11\r\nhello world\r\n"), checksumValidator)
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestDecodeAllSurfacesTrailers(t *testing.T) {
+	input := "+OK\r\n:1\r\n$3\r\nfoo\r\n:2\r\n"
+	expected := []interface{}{"OK", int64(1), "foo", int64(2)}
+
+	result, err := DecodeAll(newReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
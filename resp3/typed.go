@@ -0,0 +1,365 @@
+package resp3
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeStringSlice decodes an array (or set) reply whose elements are all strings,
+// such as the replies to KEYS or SMEMBERS, straight into a []string instead of a
+// []interface{} that callers would have to assert element by element. Simple
+// strings, bulk strings and verbatim strings are all accepted as strings.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - []string: The elements of the reply in order, or nil for a null array or the
+//     RESP3 null "_".
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is not an array or set or holds an element that is not
+//     a string, or a decoding error. The whole reply is consumed even when an element
+//     is rejected, so the reader stays positioned at the next reply.
+//
+// Example usage:
+//
+//	keys, err := DecodeStringSlice(reader) // "*2\r\n$1\r\na\r\n+b\r\n" -> []string{"a", "b"}
+func DecodeStringSlice(reader *bufio.Reader) ([]string, error) {
+	return (&Decoder{reader: reader}).DecodeStringSlice()
+}
+
+// DecodeStringSlice decodes the next reply as a []string, see the package-level
+// DecodeStringSlice function.
+func (d *Decoder) DecodeStringSlice() ([]string, error) {
+	values, err := d.decodeStrings("*~")
+	return values, err
+}
+
+// DecodeStringMap decodes a map reply whose keys and values are all strings, such
+// as the reply to HGETALL, straight into a map[string]string. The flat array of
+// alternating fields and values that RESP2 servers send for such replies is
+// accepted as well. Strings of any kind are accepted, as in DecodeStringSlice.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - map[string]string: The entries of the reply, or nil for a null map or array or
+//     the RESP3 null "_".
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is not a map or array, an array has an odd number of
+//     elements, or a key or value is not a string, or a decoding error. The whole
+//     reply is consumed even when it is rejected.
+//
+// Example usage:
+//
+//	fields, err := DecodeStringMap(reader) // "%2\r\n+f\r\n$1\r\nv\r\n" -> map[string]string{"f": "v"}
+func DecodeStringMap(reader *bufio.Reader) (map[string]string, error) {
+	return (&Decoder{reader: reader}).DecodeStringMap()
+}
+
+// DecodeStringMap decodes the next reply as a map[string]string, see the
+// package-level DecodeStringMap function. Duplicate keys are rejected with
+// ErrProtocol when RejectDuplicateKeys is set; otherwise the last value wins.
+func (d *Decoder) DecodeStringMap() (map[string]string, error) {
+	values, err := d.decodeStrings("%*")
+	if err != nil || values == nil {
+		return nil, err
+	}
+
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("reply has a key without a value: %w", ErrProtocol)
+	}
+
+	m := make(map[string]string, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		if _, exists := m[values[i]]; exists && d.RejectDuplicateKeys {
+			return nil, fmt.Errorf("duplicate map key %q: %w", values[i], ErrProtocol)
+		}
+		m[values[i]] = values[i+1]
+	}
+	return m, nil
+}
+
+// DecodeInt decodes an integer reply, also accepting the numeric simple, bulk
+// and verbatim strings that RESP2 servers may send for numbers, such as the
+// "$2\r\n42\r\n" some return for INCR.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - int64: The decoded integer.
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is neither an integer nor a string holding one, or a
+//     decoding error.
+//
+// Example usage:
+//
+//	n, err := DecodeInt(reader) // ":42\r\n" or "$2\r\n42\r\n" -> 42
+func DecodeInt(reader *bufio.Reader) (int64, error) {
+	return (&Decoder{reader: reader}).DecodeInt()
+}
+
+// DecodeInt decodes the next reply as an int64, see the package-level DecodeInt
+// function.
+func (d *Decoder) DecodeInt() (int64, error) {
+	value, err := d.Decode()
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil // Decoded with NativeInt
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("integer %d overflows int64: %w", v, ErrProtocol)
+		}
+		return int64(v), nil
+	case *RespError:
+		return 0, v
+	}
+
+	s, ok := stringValue(value)
+	if !ok {
+		return 0, fmt.Errorf("expected an integer reply, got %T: %w", value, ErrProtocol)
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q: %w: %w", s, ErrProtocol, err)
+	}
+	return n, nil
+}
+
+// DecodeTime decodes a timestamp written by Encode from a time.Time: an integer
+// number of Unix milliseconds, or a string in RFC 3339 format as written under the
+// RFC3339 TimeFormat. Strings holding an integer, as RESP2 servers may send, are
+// read as integers. A null reply, as written under ZeroTimeAsNull, decodes as the
+// zero time.Time.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - time.Time: The decoded time, in the local time zone for integer timestamps.
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is neither an integer nor a string holding a timestamp,
+//     or a decoding error.
+//
+// Example usage:
+//
+//	t, err := DecodeTime(reader) // ":1700000000000\r\n" -> time.UnixMilli(1700000000000)
+func DecodeTime(reader *bufio.Reader) (time.Time, error) {
+	return (&Decoder{reader: reader}).DecodeTime()
+}
+
+// DecodeTime decodes the next reply as a time.Time, see the package-level
+// DecodeTime function. Integer timestamps are read as Unix seconds when the
+// Decoder's TimeFormat is UnixSeconds.
+func (d *Decoder) DecodeTime() (time.Time, error) {
+	value, err := d.Decode()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var n int64
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case *RespError:
+		return time.Time{}, v
+	case int64:
+		n = v
+	case int:
+		n = int64(v) // Decoded with NativeInt
+	default:
+		s, ok := stringValue(value)
+		if !ok {
+			return time.Time{}, fmt.Errorf("expected a timestamp reply, got %T: %w", value, ErrProtocol)
+		}
+		if n, err = strconv.ParseInt(s, 10, 64); err != nil {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid timestamp %q: %w: %w", s, ErrProtocol, err)
+			}
+			return t, nil
+		}
+	}
+
+	if d.TimeFormat == UnixSeconds {
+		return time.Unix(n, 0), nil
+	}
+	return time.UnixMilli(n), nil
+}
+
+// DecodeComplex decodes a complex number written by Encode, a two-element array
+// holding the real and then the imaginary part. The parts may be doubles or
+// integers, or their text in bulk strings as RESP2 sends doubles.
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - complex128: The decoded complex number.
+//   - error: The decoded *RespError if the reply is an error reply, an error wrapping
+//     ErrProtocol if the reply is not a two-element array of numbers, or a decoding error.
+//
+// Example usage:
+//
+//	c, err := DecodeComplex(reader) // "*2\r\n,1.5\r\n,-2\r\n" -> complex(1.5, -2)
+func DecodeComplex(reader *bufio.Reader) (complex128, error) {
+	return (&Decoder{reader: reader}).DecodeComplex()
+}
+
+// DecodeComplex decodes the next reply as a complex number, see the package-level
+// DecodeComplex function.
+func (d *Decoder) DecodeComplex() (complex128, error) {
+	value, err := d.Decode()
+	if err != nil {
+		return 0, err
+	}
+	if respErr, ok := value.(*RespError); ok {
+		return 0, respErr
+	}
+
+	parts, ok := value.([]interface{})
+	if !ok || len(parts) != 2 {
+		return 0, fmt.Errorf("expected a two-element array for a complex number, got %T: %w", value, ErrProtocol)
+	}
+
+	re, err := floatValue(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("real part: %w", err)
+	}
+	im, err := floatValue(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("imaginary part: %w", err)
+	}
+	return complex(re, im), nil
+}
+
+// decodeStrings decodes a reply that must be an aggregate of one of the given
+// types holding only strings, returning its elements.
+func (d *Decoder) decodeStrings(types string) ([]string, error) {
+	start := d.offset
+	defer func() { d.lastFrameSize = int(d.offset - start) }()
+
+	dataType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.IndexByte(types, dataType) < 0 {
+		// Consume the whole reply so the stream stays aligned
+		value, err := d.decodeFrame(dataType)
+		if err != nil {
+			return nil, d.frameError(dataType, err)
+		}
+		if respErr, ok := value.(*RespError); ok {
+			return nil, respErr
+		}
+		if dataType == '_' {
+			return nil, nil // RESP3 null, written for missing values like "*-1"
+		}
+		return nil, d.replyError(dataType, fmt.Errorf("expected an aggregate reply, got %T: %w", value, ErrProtocol))
+	}
+
+	values, elementErr, err := d.decodeStringElements(dataType)
+	if err != nil {
+		return nil, d.frameError(dataType, err)
+	}
+	return values, d.replyError(dataType, elementErr)
+}
+
+// decodeStringElements decodes the elements of an aggregate whose type marker has
+// already been read, requiring each of them to be a string. The first element that
+// is not a string is reported as elementErr once the whole aggregate is consumed.
+func (d *Decoder) decodeStringElements(dataType byte) (values []string, elementErr error, err error) {
+	if err := d.checkType(dataType); err != nil {
+		return nil, nil, err
+	}
+
+	count, streamed, err := d.readCount()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if count == -1 {
+		return nil, nil, nil // Null aggregate
+	}
+
+	values = make([]string, 0, min(count, maxPreallocatedElements))
+
+	err = d.readElements(count, streamed, func() error {
+		element, err := d.decode()
+		if err != nil {
+			return err
+		}
+
+		value, ok := stringValue(element)
+		if !ok && elementErr == nil {
+			elementErr = fmt.Errorf("element %d is %T, not a string: %w", len(values), element, ErrProtocol)
+		}
+
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if elementErr != nil {
+		return nil, elementErr, nil
+	}
+	return values, nil, nil
+}
+
+// floatValue returns the value of a decoded number of any kind as a float64.
+func floatValue(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil // Decoded with NativeInt
+	case uint64:
+		return float64(n), nil
+	case *big.Float:
+		f, _ := n.Float64()
+		return f, nil
+	}
+
+	if s, ok := stringValue(v); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid double %q: %w: %w", s, ErrProtocol, err)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("%T is not a number: %w", v, ErrProtocol)
+}
+
+// stringValue returns the text of a decoded string of any kind.
+func stringValue(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case SimpleString:
+		return string(s), true
+	case []byte:
+		return string(s), true
+	case VerbatimString:
+		return s.Content, true
+	}
+	return "", false
+}
@@ -0,0 +1,293 @@
+package resp3
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeStringSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "Bulk and simple strings", input: "*3\r\n$1\r\na\r\n+b\r\n=6\r\ntxt:cc\r\n", expected: []string{"a", "b", "cc"}},
+		{name: "Set", input: "~2\r\n+x\r\n+y\r\n", expected: []string{"x", "y"}},
+		{name: "Streamed array", input: "*?\r\n+a\r\n.\r\n", expected: []string{"a"}},
+		{name: "Empty array", input: "*0\r\n", expected: []string{}},
+		{name: "Null array", input: "*-1\r\n", expected: nil},
+		{name: "RESP3 null", input: "_\r\n", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeStringSlice(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeStringSliceErrors(t *testing.T) {
+	reader := newReader("*3\r\n+a\r\n:1\r\n+c\r\n+next\r\n")
+	if _, err := DecodeStringSlice(reader); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for an integer element, got %v", err)
+	}
+
+	// The rejected reply must have been consumed entirely
+	if next, err := Decode(reader); err != nil || next != "next" {
+		t.Errorf("expected next frame, got %v (err %v)", next, err)
+	}
+
+	if _, err := DecodeStringSlice(newReader("+OK\r\n")); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a non-array reply, got %v", err)
+	}
+
+	var respErr *RespError
+	if _, err := DecodeStringSlice(newReader("-ERR boom\r\n")); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+}
+
+func TestDecodeInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int64
+	}{
+		{name: "Integer", input: ":42\r\n", expected: 42},
+		{name: "Negative integer", input: ":-7\r\n", expected: -7},
+		{name: "Bulk string", input: "$2\r\n42\r\n", expected: 42},
+		{name: "Simple string", input: "+-9223372036854775808\r\n", expected: math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeInt(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+
+	for _, input := range []string{"$3\r\nabc\r\n", "$3\r\n4.5\r\n", "+9223372036854775808\r\n", "#t\r\n", "_\r\n"} {
+		if _, err := DecodeInt(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("DecodeInt(%q) expected ErrProtocol, got %v", input, err)
+		}
+	}
+
+	var respErr *RespError
+	if _, err := DecodeInt(newReader("-ERR value is not an integer\r\n")); !errors.As(err, &respErr) {
+		t.Errorf("expected *RespError, got %v", err)
+	}
+}
+
+func TestDecodeIntNativeInt(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader(":42\r\n:-7\r\n:18446744073709551615\r\n"))
+	decoder.NativeInt = true
+
+	for _, expected := range []int64{42, -7} {
+		result, err := decoder.DecodeInt()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result != expected {
+			t.Errorf("expected %d, got %d", expected, result)
+		}
+	}
+
+	// Integers above math.MaxInt64 decode as uint64, which int64 cannot hold
+	if _, err := decoder.DecodeInt(); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for an integer above math.MaxInt64, got %v", err)
+	}
+}
+
+func TestDecodeComplex(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected complex128
+	}{
+		{name: "Doubles", input: "*2\r\n,1.5\r\n,-2\r\n", expected: complex(1.5, -2)},
+		{name: "Integers", input: "*2\r\n:3\r\n:0\r\n", expected: complex(3, 0)},
+		{name: "RESP2 bulk strings", input: "*2\r\n$3\r\n0.5\r\n$3\r\ninf\r\n", expected: complex(0.5, math.Inf(1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeComplex(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	for _, input := range []string{"*1\r\n,1\r\n", "*2\r\n,1\r\n#t\r\n", "*2\r\n,1\r\n+x\r\n", ",1\r\n"} {
+		if _, err := DecodeComplex(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("DecodeComplex(%q) expected ErrProtocol, got %v", input, err)
+		}
+	}
+}
+
+func TestDecodeComplexNativeInt(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("*2\r\n:3\r\n:-4\r\n*2\r\n:18446744073709551615\r\n:0\r\n"))
+	decoder.NativeInt = true
+
+	for _, expected := range []complex128{complex(3, -4), complex(math.MaxUint64, 0)} {
+		result, err := decoder.DecodeComplex()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result != expected {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	}
+}
+
+func TestComplexRoundTrip(t *testing.T) {
+	for _, c := range []complex128{complex(1, 2), complex(-0.1, 1e-300), complex(math.MaxFloat64, -math.SmallestNonzeroFloat64)} {
+		encoded, err := Encode(c)
+		if err != nil {
+			t.Fatalf("Encode(%v) error = %v", c, err)
+		}
+
+		result, err := DecodeComplex(newReader(encoded))
+		if err != nil {
+			t.Fatalf("DecodeComplex(%q) error = %v", encoded, err)
+		}
+		if result != c {
+			t.Errorf("expected %v, got %v", c, result)
+		}
+	}
+}
+
+func TestDecodeTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{name: "Unix milliseconds", input: ":1700000000123\r\n", expected: time.UnixMilli(1700000000123)},
+		{name: "RESP2 bulk string", input: "$4\r\n1500\r\n", expected: time.UnixMilli(1500)},
+		{name: "RFC 3339", input: "$27\r\n2024-05-01T10:20:30.5+02:00\r\n", expected: time.Date(2024, 5, 1, 8, 20, 30, 5e8, time.UTC)},
+		{name: "Null", input: "_\r\n", expected: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeTime(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	for _, input := range []string{"$5\r\nlater\r\n", ",1.5\r\n", "#t\r\n"} {
+		if _, err := DecodeTime(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("DecodeTime(%q) expected ErrProtocol, got %v", input, err)
+		}
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 20, 30, 123456789, time.UTC)
+
+	for _, format := range []TimeFormat{UnixMillis, UnixSeconds, RFC3339} {
+		var sb strings.Builder
+		encoder := NewEncoder(&sb)
+		encoder.TimeFormat = format
+		if err := encoder.Encode(ts); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+
+		decoder := NewDecoder(strings.NewReader(sb.String()))
+		decoder.TimeFormat = format
+		result, err := decoder.DecodeTime()
+		if err != nil {
+			t.Fatalf("DecodeTime(%q) error = %v", sb.String(), err)
+		}
+
+		want := ts
+		switch format {
+		case UnixMillis:
+			want = ts.Truncate(time.Millisecond)
+		case UnixSeconds:
+			want = ts.Truncate(time.Second)
+		}
+		if !result.Equal(want) {
+			t.Errorf("format %d: expected %v, got %v", format, want, result)
+		}
+	}
+}
+
+func BenchmarkDecodeStringSlice(b *testing.B) {
+	input := "*5\r\n$4\r\nkey1\r\n$4\r\nkey2\r\n$4\r\nkey3\r\n$4\r\nkey4\r\n$4\r\nkey5\r\n"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeStringSlice(newReader(input)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeStringMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{name: "RESP3 map", input: "%4\r\n+f1\r\n$2\r\nv1\r\n+f2\r\n+v2\r\n", expected: map[string]string{"f1": "v1", "f2": "v2"}},
+		{name: "RESP2 flat array", input: "*4\r\n$2\r\nf1\r\n$2\r\nv1\r\n$2\r\nf2\r\n$2\r\nv2\r\n", expected: map[string]string{"f1": "v1", "f2": "v2"}},
+		{name: "Empty map", input: "%0\r\n", expected: map[string]string{}},
+		{name: "Null map", input: "%-1\r\n", expected: nil},
+		{name: "RESP3 null", input: "_\r\n", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeStringMap(newReader(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDecodeStringMapErrors(t *testing.T) {
+	for _, input := range []string{
+		"%2\r\n+f\r\n:1\r\n",       // Integer value
+		"*3\r\n+a\r\n+b\r\n+c\r\n", // Odd number of elements
+		"~2\r\n+a\r\n+b\r\n",       // Set
+	} {
+		if _, err := DecodeStringMap(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("DecodeStringMap(%q) expected ErrProtocol, got %v", input, err)
+		}
+	}
+
+	decoder := NewDecoder(newReader("%4\r\n+f\r\n+a\r\n+f\r\n+b\r\n"))
+	decoder.RejectDuplicateKeys = true
+	if _, err := decoder.DecodeStringMap(); !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol for a duplicate key, got %v", err)
+	}
+}
@@ -0,0 +1,424 @@
+package resp3
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RespType identifies the wire type of a Value. Its value is the type marker
+// that starts frames of that type, e.g. '+' for a simple string.
+type RespType byte
+
+// The RESP3 types a Value can hold.
+const (
+	TypeSimpleString   RespType = '+'
+	TypeError          RespType = '-'
+	TypeInteger        RespType = ':'
+	TypeBulkString     RespType = '$'
+	TypeArray          RespType = '*'
+	TypeNull           RespType = '_'
+	TypeBoolean        RespType = '#'
+	TypeDouble         RespType = ','
+	TypeVerbatimString RespType = '='
+	TypeBlobError      RespType = '!'
+	TypeMap            RespType = '%'
+	TypeSet            RespType = '~'
+	TypePush           RespType = '>'
+)
+
+// String returns the name of the type, such as "simple string".
+func (t RespType) String() string {
+	switch t {
+	case TypeSimpleString:
+		return "simple string"
+	case TypeError:
+		return "error"
+	case TypeInteger:
+		return "integer"
+	case TypeBulkString:
+		return "bulk string"
+	case TypeArray:
+		return "array"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeDouble:
+		return "double"
+	case TypeVerbatimString:
+		return "verbatim string"
+	case TypeBlobError:
+		return "blob error"
+	case TypeMap:
+		return "map"
+	case TypeSet:
+		return "set"
+	case TypePush:
+		return "push"
+	}
+	return fmt.Sprintf("RespType(%q)", byte(t))
+}
+
+// Value is a RESP3 value tagged with its wire type. Unlike the native Go types
+// returned by Decode, it keeps apart the types that those collapse, such as
+// simple and bulk strings, sets and arrays, or the null forms of each type, so
+// a Value encodes back to the same type it was decoded from.
+//
+// Only the fields matching Type are used:
+//
+//   - TypeSimpleString, TypeBulkString: Str holds the string.
+//   - TypeVerbatimString: Str holds the whole payload, format prefix included,
+//     such as "txt:Some string".
+//   - TypeError, TypeBlobError: Err holds the error, a *RespError when decoded.
+//   - TypeInteger: Int holds the integer. Integers above math.MaxInt64, which
+//     Decode returns as uint64, leave Int at zero and are held in Str only.
+//   - TypeDouble: Float holds the double.
+//   - TypeBoolean: Bool holds the boolean.
+//   - TypeArray, TypeSet, TypePush: Array holds the elements.
+//   - TypeMap: Map holds the entries, in wire order.
+//
+// Decoded errors, integers and doubles also keep their text as received in Str.
+// Encoding writes that text in place of the one formatted from Err, Int or Float
+// as long as it still represents the same value, so ",1.5" is not re-encoded as
+// ",1.500000".
+//
+// IsNull marks the null form of a type, such as the null bulk string "$-1" or
+// the null array "*-1"; the RESP3 null "_" is TypeNull.
+type Value struct {
+	Type   RespType
+	Str    string
+	Int    int64
+	Float  float64
+	Bool   bool
+	Array  []Value
+	Map    []KV
+	Err    error
+	IsNull bool
+}
+
+// KV is a single entry of a map Value.
+type KV struct {
+	Key   Value
+	Value Value
+}
+
+// DecodeValue decodes the next reply from reader as a Value, preserving its exact
+// wire type, such as simple versus bulk string, integer versus double or set
+// versus array, along with map entry order and duplicate keys. Encoding the
+// result with EncodeValue reproduces the input byte for byte, except that
+// streamed strings and aggregates come back in their counted form and that
+// lengths and counts are written without padding such as "$02".
+//
+// Parameters:
+//   - reader *bufio.Reader: The reader positioned at the start of the reply.
+//
+// Returns:
+//   - Value: The decoded reply. Error replies are Values of type TypeError or
+//     TypeBlobError rather than errors.
+//   - error: io.EOF if no more data is available, or a decoding error.
+//
+// Example usage:
+//
+//	v, err := DecodeValue(reader) // "~1\r\n+a\r\n" -> Value{Type: TypeSet, Array: []Value{{Type: TypeSimpleString, Str: "a"}}}
+func DecodeValue(reader *bufio.Reader) (Value, error) {
+	return (&Decoder{reader: reader}).DecodeValue()
+}
+
+// DecodeValue decodes the next reply as a Value, see the package-level
+// DecodeValue function. It behaves like Decode with Values set.
+func (d *Decoder) DecodeValue() (Value, error) {
+	start := d.offset
+	value, err := d.decodeValue()
+	d.lastFrameSize = int(d.offset - start)
+	return value, err
+}
+
+// EncodeValue encodes v in the wire form of its Type, the inverse of DecodeValue.
+//
+// Parameters:
+//   - v Value: The value to encode.
+//
+// Returns:
+//   - string: The RESP3 encoding of v.
+//   - error: An error wrapping ErrUnsupportedEncodeType if v, or a value nested in
+//     it, has an unknown Type or is null for a type without a null form, or an error
+//     if a simple string or error holds CR or LF.
+//
+// Example usage:
+//
+//	encoded, err := EncodeValue(Value{Type: TypeBulkString, Str: "OK"}) // "$2\r\nOK\r\n"
+func EncodeValue(v Value) (string, error) {
+	var sb strings.Builder
+	if err := (&Encoder{}).encodeTagged(&sb, v); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// decodeValue reads the next frame as a Value.
+func (d *Decoder) decodeValue() (Value, error) {
+	dataType, err := d.readByte()
+	if err != nil {
+		return Value{}, err
+	}
+
+	value, err := d.decodeValueFrame(dataType)
+	return value, d.frameError(dataType, err)
+}
+
+// decodeValueFrame decodes the rest of a frame whose type marker has already
+// been read as a Value.
+func (d *Decoder) decodeValueFrame(dataType byte) (Value, error) {
+	if err := d.checkType(dataType); err != nil {
+		return Value{}, err
+	}
+
+	value := Value{Type: RespType(dataType)}
+
+	switch dataType {
+	case '*', '~', '%', '>':
+		count, streamed, err := d.readCount()
+		if err != nil {
+			return Value{}, err
+		}
+		if count == -1 && dataType == '>' {
+			return Value{}, fmt.Errorf("push has no null form: %w", ErrProtocol)
+		}
+		if count == -1 {
+			value.IsNull = true
+			return value, nil
+		}
+
+		elements := make([]Value, 0, min(count, maxPreallocatedElements))
+		err = d.readElements(count, streamed, func() error {
+			element, err := d.decodeValue()
+			elements = append(elements, element)
+			return err
+		})
+		if err != nil {
+			return Value{}, err
+		}
+
+		if dataType != '%' {
+			value.Array = elements
+			return value, nil
+		}
+
+		if len(elements)%2 != 0 {
+			return Value{}, fmt.Errorf("map has a key without a value: %w", ErrProtocol)
+		}
+		value.Map = make([]KV, 0, len(elements)/2)
+		for i := 0; i < len(elements); i += 2 {
+			value.Map = append(value.Map, KV{Key: elements[i], Value: elements[i+1]})
+		}
+		return value, nil
+
+	case '+', '-', ':', ',':
+		// Read here rather than through decodeFrame to keep the text as received
+		line, err := d.readLine()
+		if err == io.EOF {
+			return Value{}, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return Value{}, err
+		}
+		value.Str = line
+
+		switch dataType {
+		case '-':
+			value.Err = newRespError(line)
+		case ':':
+			if len(line) == 0 {
+				return Value{}, io.ErrUnexpectedEOF
+			}
+			if value.Int, err = strconv.ParseInt(line, 10, 64); err != nil {
+				if _, uerr := strconv.ParseUint(strings.TrimPrefix(line, "+"), 10, 64); uerr == nil {
+					value.Int = 0 // Above math.MaxInt64, kept as text in Str only
+					break
+				}
+				return Value{}, fmt.Errorf("invalid integer %q: %w: %w", line, ErrProtocol, err)
+			}
+		case ',':
+			if value.Float, err = strconv.ParseFloat(line, 64); err != nil {
+				return Value{}, fmt.Errorf("invalid double %q: %w: %w", line, ErrProtocol, err)
+			}
+		}
+		return value, nil
+
+	case '$', '=', '!':
+		// Read here rather than through decodeFrame, whose options such as
+		// RawBytes would drop the format of verbatim strings
+		lengthStr, err := d.readLine()
+		if err == io.EOF {
+			return Value{}, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return Value{}, err
+		}
+
+		if lengthStr == "?" && dataType == '$' {
+			streamed, err := d.decodeStreamedString()
+			if err != nil {
+				return Value{}, err
+			}
+			value.Str, _ = stringValue(streamed)
+			return value, nil
+		}
+
+		length, err := parseLength(lengthStr)
+		if err != nil {
+			return Value{}, err
+		}
+		if length == -1 {
+			if dataType == '!' {
+				return Value{}, fmt.Errorf("blob error has no null form: %w", ErrProtocol)
+			}
+			value.IsNull = true
+			return value, nil
+		}
+
+		payload, err := d.readPayload(length)
+		if err != nil {
+			return Value{}, err
+		}
+		value.Str = string(payload)
+		if dataType == '!' {
+			value.Err = newRespError(value.Str)
+		}
+		return value, d.readCRLF()
+	}
+
+	decoded, err := d.decodeFrame(dataType)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch v := decoded.(type) {
+	case nil:
+		value.IsNull = dataType != '_'
+	case bool:
+		value.Bool = v
+	default:
+		return Value{}, fmt.Errorf("unexpected %T decoding %s: %w", decoded, value.Type, ErrProtocol)
+	}
+	return value, nil
+}
+
+// encodeTagged writes v in the wire form of its Type. Under RESP2 the types that
+// RESP2 lacks are downgraded as their native Go counterparts are, e.g. a set or
+// map to an array and a double to a bulk string.
+func (e *Encoder) encodeTagged(sb *strings.Builder, v Value) error {
+	if v.IsNull && v.Type != TypeNull {
+		switch v.Type {
+		case TypeBulkString, TypeVerbatimString, TypeArray, TypeSet, TypeMap:
+			sb.WriteString(string(e.taggedMarker(v.Type)) + "-1\r\n")
+			return nil
+		}
+		return fmt.Errorf("%s has no null form: %w", v.Type, ErrUnsupportedEncodeType)
+	}
+
+	switch v.Type {
+	case TypeSimpleString:
+		if strings.ContainsAny(v.Str, "\r\n") {
+			return fmt.Errorf("simple string %q contains CR or LF", v.Str)
+		}
+		sb.WriteString("+" + v.Str + "\r\n")
+
+	case TypeError:
+		text := errorText(v)
+		if strings.ContainsAny(text, "\r\n") {
+			return fmt.Errorf("error %q contains CR or LF", text)
+		}
+		sb.WriteString("-" + text + "\r\n")
+
+	case TypeInteger:
+		text := strconv.FormatInt(v.Int, 10)
+		if parsed, err := strconv.ParseInt(v.Str, 10, 64); err == nil && parsed == v.Int {
+			text = v.Str // As received, e.g. with a leading "+"
+		} else if parsed, err := strconv.ParseUint(strings.TrimPrefix(v.Str, "+"), 10, 64); err == nil && parsed > math.MaxInt64 && v.Int == 0 {
+			text = v.Str // Above math.MaxInt64, which Int cannot hold
+		}
+		sb.WriteString(":" + text + "\r\n")
+
+	case TypeBulkString:
+		writeBulkString(sb, v.Str)
+
+	case TypeVerbatimString:
+		if e.Protocol == RESP2 {
+			content := v.Str
+			if hasVerbatimFormat([]byte(content)) {
+				content = content[4:]
+			}
+			writeBulkString(sb, content)
+			break
+		}
+		sb.WriteString("=" + strconv.Itoa(len(v.Str)) + "\r\n" + v.Str + "\r\n")
+
+	case TypeBlobError:
+		e.writeBlobError(sb, errorText(v))
+
+	case TypeNull:
+		e.writeNull(sb)
+
+	case TypeBoolean:
+		return e.encodeValue(sb, v.Bool) // Written as ":1" or ":0" under RESP2
+
+	case TypeDouble:
+		text := formatDouble(v.Float, 64)
+		if parsed, err := strconv.ParseFloat(v.Str, 64); err == nil && (parsed == v.Float || math.IsNaN(parsed) && math.IsNaN(v.Float)) {
+			text = v.Str // As received, e.g. "1.5" rather than "1.500000"
+		}
+		e.writeDouble(sb, text)
+
+	case TypeArray, TypeSet, TypePush:
+		writeHeader(sb, e.taggedMarker(v.Type), len(v.Array))
+		for i, elem := range v.Array {
+			if err := e.encodeTagged(sb, elem); err != nil {
+				return fmt.Errorf("encode index %d: %w", i, err)
+			}
+		}
+
+	case TypeMap:
+		writeHeader(sb, e.mapMarker(), len(v.Map)*2) // Maps count their keys and values
+		for _, kv := range v.Map {
+			if err := e.encodeTagged(sb, kv.Key); err != nil {
+				return err
+			}
+			if err := e.encodeTagged(sb, kv.Value); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported value type %s: %w", v.Type, ErrUnsupportedEncodeType)
+	}
+	return nil
+}
+
+// taggedMarker returns the type marker written for a Value of type t, which
+// under RESP2 is '*' for the aggregates and '$' for the strings that RESP2 lacks.
+func (e *Encoder) taggedMarker(t RespType) byte {
+	if e.Protocol != RESP2 {
+		return byte(t)
+	}
+	switch t {
+	case TypeSet, TypePush, TypeMap:
+		return '*'
+	case TypeVerbatimString:
+		return '$'
+	}
+	return byte(t)
+}
+
+// errorText returns the text of an error Value: Str if it still describes Err,
+// which keeps the text as received, and the text of Err otherwise.
+func errorText(v Value) string {
+	if v.Err == nil || v.Str != "" && newRespError(v.Str).Error() == v.Err.Error() {
+		return v.Str
+	}
+	return v.Err.Error()
+}
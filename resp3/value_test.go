@@ -0,0 +1,276 @@
+package resp3
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEncodeValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Value
+		expected string
+	}{
+		{name: "Simple string", input: Value{Type: TypeSimpleString, Str: "OK"}, expected: "+OK\r\n"},
+		{name: "Bulk string", input: Value{Type: TypeBulkString, Str: "OK"}, expected: "$2\r\nOK\r\n"},
+		{name: "Null bulk string", input: Value{Type: TypeBulkString, IsNull: true}, expected: "$-1\r\n"},
+		{name: "Null", input: Value{Type: TypeNull}, expected: "_\r\n"},
+		{name: "Integer", input: Value{Type: TypeInteger, Int: -3}, expected: ":-3\r\n"},
+		{name: "Double", input: Value{Type: TypeDouble, Float: 2.5}, expected: ",2.500000\r\n"},
+		{name: "Boolean", input: Value{Type: TypeBoolean, Bool: true}, expected: "#t\r\n"},
+		{name: "Error", input: Value{Type: TypeError, Err: errors.New("ERR boom")}, expected: "-ERR boom\r\n"},
+		{name: "Blob error", input: Value{Type: TypeBlobError, Err: errors.New("ERR a\r\nb")}, expected: "!8\r\nERR a\r\nb\r\n"},
+		{name: "Verbatim string", input: Value{Type: TypeVerbatimString, Str: "txt:hi"}, expected: "=6\r\ntxt:hi\r\n"},
+		{
+			name:     "Set",
+			input:    Value{Type: TypeSet, Array: []Value{{Type: TypeInteger, Int: 1}, {Type: TypeSimpleString, Str: "a"}}},
+			expected: "~2\r\n:1\r\n+a\r\n",
+		},
+		{
+			name:     "Map",
+			input:    Value{Type: TypeMap, Map: []KV{{Key: Value{Type: TypeBulkString, Str: "k"}, Value: Value{Type: TypeArray, IsNull: true}}}},
+			expected: "%2\r\n$1\r\nk\r\n*-1\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []Value{{}, {Type: TypeInteger, IsNull: true}, {Type: TypeArray, Array: []Value{{Type: 'x'}}}} {
+		if _, err := Encode(input); !errors.Is(err, ErrUnsupportedEncodeType) {
+			t.Errorf("Encode(%+v) expected ErrUnsupportedEncodeType, got %v", input, err)
+		}
+	}
+}
+
+func TestEncodeValueRESP2(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Value
+		expected string
+	}{
+		{name: "Null", input: Value{Type: TypeNull}, expected: "$-1\r\n"},
+		{name: "Boolean", input: Value{Type: TypeBoolean, Bool: true}, expected: ":1\r\n"},
+		{name: "Double", input: Value{Type: TypeDouble, Float: 2.5, Str: "2.5"}, expected: "$3\r\n2.5\r\n"},
+		{name: "Verbatim string", input: Value{Type: TypeVerbatimString, Str: "txt:hi"}, expected: "$2\r\nhi\r\n"},
+		{name: "Null verbatim string", input: Value{Type: TypeVerbatimString, IsNull: true}, expected: "$-1\r\n"},
+		{name: "Blob error", input: Value{Type: TypeBlobError, Err: errors.New("ERR a\r\nb")}, expected: "-ERR a  b\r\n"},
+		{name: "Null set", input: Value{Type: TypeSet, IsNull: true}, expected: "*-1\r\n"},
+		{
+			name:     "Push",
+			input:    Value{Type: TypePush, Array: []Value{{Type: TypeSimpleString, Str: "message"}, {Type: TypeBoolean}}},
+			expected: "*2\r\n+message\r\n:0\r\n",
+		},
+		{
+			name:     "Map",
+			input:    Value{Type: TypeMap, Map: []KV{{Key: Value{Type: TypeBulkString, Str: "k"}, Value: Value{Type: TypeNull}}}},
+			expected: "*2\r\n$1\r\nk\r\n$-1\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			encoder := NewEncoder(&sb)
+			encoder.Protocol = RESP2
+
+			if err := encoder.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Encode() = %q, want %q", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecoderValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Value
+	}{
+		{name: "Simple string", input: "+OK\r\n", expected: Value{Type: TypeSimpleString, Str: "OK"}},
+		{name: "Bulk string", input: "$2\r\nOK\r\n", expected: Value{Type: TypeBulkString, Str: "OK"}},
+		{name: "Streamed bulk string", input: "$?\r\n;2\r\nOK\r\n;0\r\n", expected: Value{Type: TypeBulkString, Str: "OK"}},
+		{name: "Null bulk string", input: "$-1\r\n", expected: Value{Type: TypeBulkString, IsNull: true}},
+		{name: "Null", input: "_\r\n", expected: Value{Type: TypeNull}},
+		{name: "Integer", input: ":42\r\n", expected: Value{Type: TypeInteger, Int: 42, Str: "42"}},
+		{name: "Double", input: ",1.5\r\n", expected: Value{Type: TypeDouble, Float: 1.5, Str: "1.5"}},
+		{name: "Boolean", input: "#f\r\n", expected: Value{Type: TypeBoolean}},
+		{name: "Error", input: "-ERR boom\r\n", expected: Value{Type: TypeError, Err: &RespError{Code: "ERR", Message: "boom"}, Str: "ERR boom"}},
+		{name: "Verbatim string", input: "=6\r\nmkd:hi\r\n", expected: Value{Type: TypeVerbatimString, Str: "mkd:hi"}},
+		{
+			name:     "Set",
+			input:    "~2\r\n:1\r\n:1\r\n",
+			expected: Value{Type: TypeSet, Array: []Value{{Type: TypeInteger, Int: 1, Str: "1"}, {Type: TypeInteger, Int: 1, Str: "1"}}},
+		},
+		{
+			name:  "Map keeps order and duplicates",
+			input: "%4\r\n+b\r\n*0\r\n+a\r\n~-1\r\n",
+			expected: Value{Type: TypeMap, Map: []KV{
+				{Key: Value{Type: TypeSimpleString, Str: "b"}, Value: Value{Type: TypeArray, Array: []Value{}}},
+				{Key: Value{Type: TypeSimpleString, Str: "a"}, Value: Value{Type: TypeSet, IsNull: true}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input))
+			decoder.Values = true
+			decoder.RawBytes = true // Has no effect on Values
+
+			result, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	inputs := []string{
+		"+OK\r\n",
+		"$2\r\nOK\r\n",
+		"$0\r\n\r\n",
+		"$-1\r\n",
+		"*-1\r\n",
+		"_\r\n",
+		":+7\r\n",
+		":-0\r\n",
+		",1.5\r\n",
+		",1e3\r\n",
+		",-inf\r\n",
+		",nan\r\n",
+		"#t\r\n",
+		"-ERR \r\n",
+		"-WRONGTYPE Operation against a key\r\n",
+		"!11\r\nERR a\r\nbcde\r\n",
+		"=11\r\ntxt:\r\nhello\r\n",
+		"=5\r\nhello\r\n",
+		"~2\r\n+a\r\n$1\r\na\r\n",
+		"%4\r\n:1\r\n+x\r\n:1\r\n$1\r\ny\r\n",
+		"*3\r\n*0\r\n%0\r\n~-1\r\n",
+		">2\r\n+message\r\n$5\r\nhello\r\n",
+	}
+
+	for _, input := range inputs {
+		reader := newReader(input)
+		value, err := DecodeValue(reader)
+		if err != nil {
+			t.Fatalf("DecodeValue(%q) error = %v", input, err)
+		}
+
+		result, err := EncodeValue(value)
+		if err != nil {
+			t.Fatalf("EncodeValue(%+v) error = %v", value, err)
+		}
+		if result != input {
+			t.Errorf("round trip of %q produced %q", input, result)
+		}
+	}
+}
+
+func TestValueMaxUint64RoundTrip(t *testing.T) {
+	input := ":" + strconv.FormatUint(math.MaxUint64, 10) + "\r\n"
+
+	value, err := DecodeValue(newReader(input))
+	if err != nil {
+		t.Fatalf("DecodeValue() error = %v", err)
+	}
+	if value.Type != TypeInteger || value.Int != 0 || value.Str != "18446744073709551615" {
+		t.Errorf("expected the integer held in Str only, got %+v", value)
+	}
+
+	result, err := EncodeValue(value)
+	if err != nil {
+		t.Fatalf("EncodeValue() error = %v", err)
+	}
+	if result != input {
+		t.Errorf("round trip of %q produced %q", input, result)
+	}
+
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.Values = true
+	if decoded, err := decoder.Decode(); err != nil || decoded.(Value).Str != value.Str {
+		t.Errorf("expected %+v from Decode with Values, got (%+v, %v)", value, decoded, err)
+	}
+
+	// A leading "+" is accepted, as by Decode, and kept as received
+	signed := ":+" + strconv.FormatUint(math.MaxUint64, 10) + "\r\n"
+	value, err = DecodeValue(newReader(signed))
+	if err != nil {
+		t.Fatalf("DecodeValue() error = %v", err)
+	}
+	if value.Type != TypeInteger || value.Int != 0 || value.Str != "+18446744073709551615" {
+		t.Errorf("expected the signed integer held in Str only, got %+v", value)
+	}
+	if result, err := EncodeValue(value); err != nil || result != signed {
+		t.Errorf("EncodeValue() = (%q, %v), want %q", result, err, signed)
+	}
+
+	// Setting Int replaces the text held in Str
+	value.Int = 1
+	if result, err := EncodeValue(value); err != nil || result != ":1\r\n" {
+		t.Errorf("EncodeValue() = (%q, %v), want %q", result, err, ":1\r\n")
+	}
+}
+
+func TestValueEncodesChangedFields(t *testing.T) {
+	value, err := DecodeValue(newReader("*2\r\n:+7\r\n,1.5\r\n"))
+	if err != nil {
+		t.Fatalf("DecodeValue() error = %v", err)
+	}
+
+	// Str no longer describes the changed values, so they are formatted afresh
+	value.Array[0].Int = 8
+	value.Array[1].Float = 2
+	result, err := EncodeValue(value)
+	if err != nil {
+		t.Fatalf("EncodeValue() error = %v", err)
+	}
+	if expected := "*2\r\n:8\r\n,2.000000\r\n"; result != expected {
+		t.Errorf("EncodeValue() = %q, want %q", result, expected)
+	}
+}
+
+func TestDecodeValueStreamed(t *testing.T) {
+	value, err := DecodeValue(newReader("*?\r\n$?\r\n;2\r\nhi\r\n;0\r\n.\r\n"))
+	if err != nil {
+		t.Fatalf("DecodeValue() error = %v", err)
+	}
+
+	// Streamed frames come back in their counted form
+	result, err := EncodeValue(value)
+	if err != nil {
+		t.Fatalf("EncodeValue() error = %v", err)
+	}
+	if expected := "*1\r\n$2\r\nhi\r\n"; result != expected {
+		t.Errorf("EncodeValue() = %q, want %q", result, expected)
+	}
+}
+
+func TestDecodeValueNegativeCounts(t *testing.T) {
+	for _, input := range []string{"*-2\r\n", "$-2\r\n", "%-3\r\n", "~-5\r\n", "=-2\r\n", "!-1\r\n", "*1\r\n*-2\r\n"} {
+		if _, err := DecodeValue(newReader(input)); !errors.Is(err, ErrProtocol) {
+			t.Errorf("DecodeValue(%q) expected ErrProtocol, got %v", input, err)
+		}
+	}
+}
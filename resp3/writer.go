@@ -0,0 +1,126 @@
+package resp3
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The primitive writers below write a single frame to the underlying writer,
+// so a reply can be streamed element by element instead of being built as a Go
+// value and passed to Encode. They honour the Protocol option like Encode does.
+//
+// The header writers only write the aggregate header: the caller is responsible
+// for writing exactly the declared number of elements after it, or the peer will
+// read the following replies as part of the aggregate.
+//
+// Example usage:
+//
+//	enc := NewEncoder(conn)
+//	enc.WriteArrayHeader(2)          // "*2\r\n"
+//	enc.WriteBulkString([]byte("a")) // "$1\r\na\r\n"
+//	enc.WriteInt(1)                  // ":1\r\n"
+
+// WriteSimpleString writes s as a simple string, such as "+OK\r\n". It fails
+// without writing anything if s contains CR or LF.
+func (e *Encoder) WriteSimpleString(s string) error {
+	if strings.ContainsAny(s, "\r\n") {
+		return fmt.Errorf("simple string %q contains CR or LF", s)
+	}
+	return e.writeFrame("+" + s + "\r\n")
+}
+
+// WriteBulkString writes b as a bulk string, such as "$5\r\nhello\r\n".
+func (e *Encoder) WriteBulkString(b []byte) error {
+	var sb strings.Builder
+	writeBulkBytes(&sb, b)
+	return e.writeFrame(sb.String())
+}
+
+// WriteInt writes i as an integer, such as ":42\r\n".
+func (e *Encoder) WriteInt(i int64) error {
+	return e.writeFrame(":" + strconv.FormatInt(i, 10) + "\r\n")
+}
+
+// WriteDouble writes f as a double, such as ",3.14\r\n", or as a bulk string of
+// the same text under RESP2.
+func (e *Encoder) WriteDouble(f float64) error {
+	var sb strings.Builder
+	e.writeDouble(&sb, formatDouble(f, 64))
+	return e.writeFrame(sb.String())
+}
+
+// WriteBool writes b as a boolean, "#t\r\n" or "#f\r\n", or as the integer 1 or 0
+// under RESP2.
+func (e *Encoder) WriteBool(b bool) error {
+	switch {
+	case e.Protocol == RESP2 && b:
+		return e.writeFrame(":1\r\n")
+	case e.Protocol == RESP2:
+		return e.writeFrame(":0\r\n")
+	case b:
+		return e.writeFrame("#t\r\n")
+	}
+	return e.writeFrame("#f\r\n")
+}
+
+// WriteError writes an error reply made of code and msg, such as
+// "-ERR unknown command\r\n". An empty msg writes the code alone. It fails
+// without writing anything if code or msg contains CR or LF.
+func (e *Encoder) WriteError(code, msg string) error {
+	text := (&RespError{Code: code, Message: msg}).Error()
+	if strings.ContainsAny(text, "\r\n") {
+		return fmt.Errorf("error %q contains CR or LF", text)
+	}
+	return e.writeFrame("-" + text + "\r\n")
+}
+
+// WriteNull writes a null, "_\r\n", or the null bulk string "$-1\r\n" under RESP2.
+func (e *Encoder) WriteNull() error {
+	var sb strings.Builder
+	e.writeNull(&sb)
+	return e.writeFrame(sb.String())
+}
+
+// WriteArrayHeader writes the header of an array of n elements, such as "*3\r\n".
+// The caller must then write the n elements.
+func (e *Encoder) WriteArrayHeader(n int) error {
+	return e.writeAggregateHeader('*', n)
+}
+
+// WriteSetHeader writes the header of a set of n elements, such as "~3\r\n", or
+// of an array under RESP2. The caller must then write the n elements.
+func (e *Encoder) WriteSetHeader(n int) error {
+	if e.Protocol == RESP2 {
+		return e.writeAggregateHeader('*', n)
+	}
+	return e.writeAggregateHeader('~', n)
+}
+
+// WriteMapHeader writes the header of a map of n entries, or of an array of
+// alternating keys and values under RESP2. As with Encode, the count on the
+// wire covers keys and values, so n entries write "%<2n>\r\n". The caller must
+// then write the n entries, each as a key followed by its value.
+func (e *Encoder) WriteMapHeader(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid map entry count %d", n)
+	}
+	return e.writeAggregateHeader(e.mapMarker(), n*2)
+}
+
+// writeAggregateHeader writes an aggregate header after rejecting negative counts.
+func (e *Encoder) writeAggregateHeader(marker byte, count int) error {
+	if count < 0 {
+		return fmt.Errorf("invalid aggregate count %d", count)
+	}
+	var sb strings.Builder
+	writeHeader(&sb, marker, count)
+	return e.writeFrame(sb.String())
+}
+
+// writeFrame writes an encoded frame to the underlying writer in a single Write call.
+func (e *Encoder) writeFrame(frame string) error {
+	_, err := io.WriteString(e.w, frame)
+	return err
+}
@@ -0,0 +1,100 @@
+package resp3
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncoderPrimitiveWriters(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+
+	writes := []func() error{
+		func() error { return encoder.WriteMapHeader(2) },
+		func() error { return encoder.WriteSimpleString("name") },
+		func() error { return encoder.WriteBulkString([]byte("redis")) },
+		func() error { return encoder.WriteSimpleString("tags") },
+		func() error { return encoder.WriteArrayHeader(5) },
+		func() error { return encoder.WriteInt(-7) },
+		func() error { return encoder.WriteDouble(1.5) },
+		func() error { return encoder.WriteBool(true) },
+		func() error { return encoder.WriteNull() },
+		func() error { return encoder.WriteError("ERR", "unknown command") },
+	}
+	for i, write := range writes {
+		if err := write(); err != nil {
+			t.Fatalf("write %d: unexpected error: %v", i, err)
+		}
+	}
+
+	expected := "%4\r\n+name\r\n$5\r\nredis\r\n+tags\r\n*5\r\n:-7\r\n,1.500000\r\n#t\r\n_\r\n-ERR unknown command\r\n"
+	if sb.String() != expected {
+		t.Fatalf("writers produced %q, want %q", sb.String(), expected)
+	}
+
+	// The streamed reply decodes like one encoded in a single call
+	decoded, err := Decode(bufio.NewReader(strings.NewReader(sb.String())))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"name": "redis",
+		"tags": []interface{}{int64(-7), 1.5, true, nil, &RespError{Code: "ERR", Message: "unknown command"}},
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("expected %#v, got %#v", want, decoded)
+	}
+}
+
+func TestEncoderPrimitiveWritersRESP2(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+	encoder.Protocol = RESP2
+
+	writes := []func() error{
+		func() error { return encoder.WriteMapHeader(1) },
+		func() error { return encoder.WriteSetHeader(0) },
+		func() error { return encoder.WriteBool(false) },
+		func() error { return encoder.WriteDouble(2.5) },
+		func() error { return encoder.WriteNull() },
+	}
+	for i, write := range writes {
+		if err := write(); err != nil {
+			t.Fatalf("write %d: unexpected error: %v", i, err)
+		}
+	}
+
+	expected := "*2\r\n*0\r\n:0\r\n$8\r\n2.500000\r\n$-1\r\n"
+	if sb.String() != expected {
+		t.Errorf("writers produced %q, want %q", sb.String(), expected)
+	}
+}
+
+func TestEncoderPrimitiveWritersInvalid(t *testing.T) {
+	var sb strings.Builder
+	encoder := NewEncoder(&sb)
+
+	tests := []struct {
+		name  string
+		write func() error
+	}{
+		{"simple string with CRLF", func() error { return encoder.WriteSimpleString("a\r\nb") }},
+		{"error with LF", func() error { return encoder.WriteError("ERR", "a\nb") }},
+		{"negative array count", func() error { return encoder.WriteArrayHeader(-1) }},
+		{"negative map count", func() error { return encoder.WriteMapHeader(-1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); err == nil {
+				t.Errorf("expected error, got none")
+			}
+		})
+	}
+
+	if sb.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", sb.String())
+	}
+}